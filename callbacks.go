@@ -0,0 +1,59 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// Functions called back from the form library. The preamble of a file which
+// exports functions may only contain declarations, so the C side of each
+// callback is found with the code which registers it.
+
+// #include <form.h>
+// #include <stdint.h>
+import "C"
+
+//export goncursesCheckField
+func goncursesCheckField(f *C.FIELD, id C.uintptr_t) C.int {
+	t := fieldTypes[uintptr(id)]
+	return cbool(t == nil || t.validateField((*Field)(f)))
+}
+
+//export goncursesCheckChar
+func goncursesCheckChar(c C.int, id C.uintptr_t) C.int {
+	t := fieldTypes[uintptr(id)]
+	return cbool(t == nil || t.validateChar(rune(c)))
+}
+
+//export goncursesNextChoice
+func goncursesNextChoice(f *C.FIELD, id C.uintptr_t) C.int {
+	t := fieldTypes[uintptr(id)]
+	return cbool(t != nil && t.next != nil && t.next((*Field)(f)))
+}
+
+//export goncursesPrevChoice
+func goncursesPrevChoice(f *C.FIELD, id C.uintptr_t) C.int {
+	t := fieldTypes[uintptr(id)]
+	return cbool(t != nil && t.prev != nil && t.prev((*Field)(f)))
+}
+
+//export goncursesFormHook
+func goncursesFormHook(id C.uintptr_t, hook C.int) {
+	h := formHooks[uintptr(id)]
+	if h == nil {
+		return
+	}
+	f := h.form
+	switch hook {
+	case hookFieldEnter, hookFieldLeave:
+		if fn := h.field[hook-hookFieldEnter]; fn != nil {
+			fn(f, (*Field)(C.current_field(f.form)))
+		}
+	case hookPageEnter, hookPageLeave:
+		if fn := h.page[hook-hookPageEnter]; fn != nil {
+			fn(f, int(C.form_page(f.form)))
+		}
+	}
+}
@@ -4,7 +4,7 @@
 
 package goncurses
 
-// #cgo !darwin,!openbsd,!windows pkg-config: ncurses
+// #cgo !darwin,!openbsd,!windows pkg-config: ncursesw
 // #include <curses.h>
 import "C"
 
@@ -18,6 +18,16 @@ const (
 
 type Char C.chtype
 
+// WChar is a wide character cell. It holds a single Unicode character
+// together with the attributes and color pair used to display it. Unlike
+// Char, the character is not limited to the range of a single byte and the
+// color pair is not encoded in the attribute bits.
+type WChar struct {
+	Rune rune  // character to display
+	Attr Char  // OR'd A_* attributes
+	Pair int32 // color pair, see InitPair and InitExtendedPair
+}
+
 // Text attributes
 const (
 	A_NORMAL     Char = C.A_NORMAL
@@ -265,6 +275,11 @@ const (
 	M_B4_CLICKED                 = C.BUTTON4_CLICKED
 	M_B4_DBL_CLICKED             = C.BUTTON4_DOUBLE_CLICKED
 	M_B4_TPL_CLICKED             = C.BUTTON4_TRIPLE_CLICKED
+	M_B5_PRESSED                 = C.BUTTON5_PRESSED // button 5
+	M_B5_RELEASED                = C.BUTTON5_RELEASED
+	M_B5_CLICKED                 = C.BUTTON5_CLICKED
+	M_B5_DBL_CLICKED             = C.BUTTON5_DOUBLE_CLICKED
+	M_B5_TPL_CLICKED             = C.BUTTON5_TRIPLE_CLICKED
 	M_CTRL                       = C.BUTTON_CTRL           // ctrl-click
 	M_SHIFT                      = C.BUTTON_SHIFT          // shift-click
 	M_POSITION                   = C.REPORT_MOUSE_POSITION // mouse moved
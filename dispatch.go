@@ -0,0 +1,137 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// The dispatcher is a single goroutine, locked to its OS thread, on which
+// functions passed to Do and Post are run one at a time. Functions posted
+// by the dispatcher itself are queued in pending, which only it uses, as
+// sending them to funcs could block it forever. Running is set while it runs
+// functions, as only then can a caller of Do or Post be the dispatcher.
+var dispatcher struct {
+	once    sync.Once
+	funcs   chan func()
+	pending []func()
+	running int32
+}
+
+var dispatchDebug int32
+
+func startDispatcher() {
+	dispatcher.once.Do(func() {
+		dispatcher.funcs = make(chan func(), 64)
+		go dispatch()
+	})
+}
+
+// dispatch is the dispatcher goroutine, see onDispatcher
+func dispatch() {
+	runtime.LockOSThread()
+	for f := range dispatcher.funcs {
+		atomic.StoreInt32(&dispatcher.running, 1)
+		f()
+		for len(dispatcher.pending) > 0 {
+			f = dispatcher.pending[0]
+			dispatcher.pending[0] = nil
+			dispatcher.pending = dispatcher.pending[1:]
+			f()
+		}
+		atomic.StoreInt32(&dispatcher.running, 0)
+	}
+}
+
+// Do runs f on the dispatcher goroutine and waits for it to return. Because
+// every function passed to Do or Post runs on the same goroutine, one at a
+// time, ncurses functions called from within them can never run
+// concurrently. If Do is called from the dispatcher goroutine itself, f is
+// run immediately. A panic in f is propagated to the caller of Do.
+func Do(f func()) {
+	startDispatcher()
+	if onDispatcher() {
+		f()
+		return
+	}
+	var p interface{}
+	done := make(chan struct{})
+	dispatcher.funcs <- func() {
+		defer close(done)
+		defer func() { p = recover() }()
+		f()
+	}
+	<-done
+	if p != nil {
+		panic(p)
+	}
+}
+
+// Post queues f to be run on the dispatcher goroutine and returns without
+// waiting for it. Functions are run in the order in which they were posted.
+// When called from the dispatcher goroutine itself, such as by a function
+// passed to EventLoop.Draw which schedules another, Post never blocks and f
+// is run once the function running returns. See Do for more details.
+func Post(f func()) {
+	startDispatcher()
+	if onDispatcher() {
+		dispatcher.pending = append(dispatcher.pending, f)
+		return
+	}
+	dispatcher.funcs <- f
+}
+
+// DispatchDebug turns on/off checking that the methods of Window, Pad,
+// Panel, Menu and Form are only called from within functions run by Do or
+// Post. When on, calling them from any other goroutine causes a panic,
+// making it easier to find unsafe concurrent use of ncurses during
+// development. Note that Init should then also be called via Do.
+func DispatchDebug(on bool) {
+	var v int32
+	if on {
+		v = 1
+		startDispatcher()
+	}
+	atomic.StoreInt32(&dispatchDebug, v)
+}
+
+// checkDispatch panics if dispatch debugging is on and the caller is not
+// running on the dispatcher goroutine
+func checkDispatch() {
+	if atomic.LoadInt32(&dispatchDebug) != 0 && !onDispatcher() {
+		panic("goncurses: called outside of the dispatcher goroutine; " +
+			"use Do or Post")
+	}
+}
+
+// onDispatcher returns true if called from the dispatcher goroutine. Unless
+// the dispatcher is running a function the caller can not be it, otherwise
+// the caller is the dispatcher if dispatch is at the bottom of its stack.
+func onDispatcher() bool {
+	if atomic.LoadInt32(&dispatcher.running) == 0 {
+		return false
+	}
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(2, pcs)
+	}
+	entry := reflect.ValueOf(dispatch).Pointer()
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Entry == entry {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package goncurses_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestDispatchDebug(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+	w := scr.Stdscr()
+
+	goncurses.DispatchDebug(true)
+	defer goncurses.DispatchDebug(false)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic calling Window method off dispatcher")
+			}
+		}()
+		w.Erase()
+	}()
+
+	var y, x int
+	goncurses.Do(func() {
+		w.MovePrint(1, 2, "ok")
+		goncurses.Do(func() { y, x = w.CursorYX() })
+	})
+	if y != 1 || x != 4 {
+		t.Errorf("got cursor %d, %d; want 1, 4", y, x)
+	}
+}
+
+func TestPostFromDispatcher(t *testing.T) {
+	// more functions than the dispatcher's channel holds, which would block
+	// it forever if sent there
+	const n = 1000
+	done := make(chan []int)
+	var order []int
+	goncurses.Do(func() {
+		for i := 0; i < n; i++ {
+			i := i
+			goncurses.Post(func() {
+				order = append(order, i)
+				if i == n-1 {
+					done <- order
+				}
+			})
+		}
+	})
+	select {
+	case order := <-done:
+		for i, v := range order {
+			if v != i {
+				t.Fatalf("function %d run in position %d", v, i)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the posted functions")
+	}
+}
+
+func TestDoWhileDispatcherBusy(t *testing.T) {
+	// a function passed to Do by another goroutine while the dispatcher is
+	// running one must wait for it rather than run at the same time
+	release := make(chan struct{})
+	busy := make(chan struct{})
+	go goncurses.Do(func() {
+		close(busy)
+		<-release
+	})
+	<-busy
+	ran := make(chan struct{})
+	go goncurses.Do(func() { close(ran) })
+	select {
+	case <-ran:
+		t.Fatal("function ran while the dispatcher was busy")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the function")
+	}
+}
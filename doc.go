@@ -32,6 +32,13 @@
 // concurrently. Failure to do so will result in unpredictable and
 // undefined behaviour in your program.
 //
+// The Do and Post functions implement the former approach for you: every
+// function passed to them runs on a single dispatcher goroutine, locked to
+// its OS thread, so calls made from within them are serialized. The
+// EventLoop type builds on this to deliver input as events on a channel.
+// During development, DispatchDebug can be turned on to catch ncurses
+// calls made from any other goroutine.
+//
 // The examples directory contains demonstrations of many of the capabilities
 // goncurses can provide.
 package goncurses
@@ -0,0 +1,236 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a value delivered by an EventLoop. It will be one of KeyEvent,
+// PasteEvent, *MouseEvent, ResizeEvent or TimerEvent.
+type Event interface{}
+
+// KeyEvent is delivered when a key is pressed. Function keys pressed with
+// modifiers are reported as the unmodified key, see DecodeKey, and a
+// character typed with alt held is reported with MOD_ALT set. Control
+// characters are reported as typed, without MOD_CTRL.
+type KeyEvent struct {
+	Key       Key      // key code or character, as returned by GetChar
+	Rune      rune     // character typed or zero (0) if Key is a function key
+	Modifiers Modifier // modifier keys held
+}
+
+// ResizeEvent is delivered when the terminal has been resized. Rows and Cols
+// are the new dimensions of the standard screen.
+type ResizeEvent struct {
+	Rows, Cols int
+}
+
+// TimerEvent is delivered each time a Timer created by an EventLoop expires
+type TimerEvent struct {
+	Timer *Timer
+	Time  time.Time
+}
+
+// EventPollInterval is the time, in milliseconds, an EventLoop waits for
+// input before allowing other functions to run on the dispatcher goroutine.
+var EventPollInterval = 10
+
+// EventLoop reads input on the dispatcher goroutine (see Do) and publishes it
+// as typed events on the channel returned by Events. Functions passed to Draw
+// are executed between reads, on the same goroutine, so that the application
+// never calls ncurses from any other goroutine.
+//
+// Once an EventLoop has been created, all ncurses functions must be called
+// from within a function passed to Draw, Do or Post until Stop returns.
+type EventLoop struct {
+	win    *Window
+	events chan Event
+	timers chan Event
+	quit   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewEventLoop starts an event loop reading input from the window. The
+// window's input timeout is set to EventPollInterval while the loop runs and
+// set back to blocking mode once it stops. Keypad should be turned on if
+// function key or mouse events are wanted.
+func NewEventLoop(w *Window) *EventLoop {
+	l := &EventLoop{
+		win:    w,
+		events: make(chan Event, 16),
+		timers: make(chan Event, 16),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Events returns the channel on which events are delivered. The channel is
+// closed once the loop has stopped.
+func (l *EventLoop) Events() <-chan Event {
+	return l.events
+}
+
+// Draw queues f to be run on the dispatcher goroutine and returns
+// immediately. It is the same as calling Post.
+func (l *EventLoop) Draw(f func()) {
+	Post(f)
+}
+
+// Stop ends the event loop and waits for it to finish, unless called from
+// the dispatcher goroutine in which case it returns immediately.
+func (l *EventLoop) Stop() {
+	l.once.Do(func() { close(l.quit) })
+	if !onDispatcher() {
+		<-l.done
+	}
+}
+
+// NewTimer creates a timer which delivers a TimerEvent once duration d has
+// elapsed. If repeat is true then the timer fires every d until stopped.
+func (l *EventLoop) NewTimer(d time.Duration, repeat bool) *Timer {
+	t := &Timer{loop: l, d: d, repeat: repeat}
+	t.mu.Lock()
+	t.timer = time.AfterFunc(d, t.fire)
+	t.mu.Unlock()
+	return t
+}
+
+func (l *EventLoop) run() {
+	defer close(l.done)
+	defer close(l.events)
+
+	Do(func() { l.win.Timeout(EventPollInterval) })
+	defer Do(func() { l.win.Timeout(-1) })
+
+	for {
+		var ev Event
+		Do(func() { ev = l.poll() })
+		if ev != nil && !l.publish(ev) {
+			return
+		}
+		select {
+		case <-l.quit:
+			return
+		case ev := <-l.timers:
+			if !l.publish(ev) {
+				return
+			}
+		default:
+		}
+	}
+}
+
+// poll reads the next event from the window, returning nil if none is
+// available
+func (l *EventLoop) poll() Event {
+	return readEvent(l.win)
+}
+
+// readEvent reads the next event from the window, returning nil if none is
+// available
+func readEvent(w *Window) Event {
+	r, k, err := w.GetWChar()
+	if err == nil && r == KEY_ESC {
+		return readAlt(w)
+	}
+	switch {
+	case err != nil:
+		return nil
+	case k == KEY_MOUSE:
+		if me := GetMouse(); me != nil {
+			return me
+		}
+		return nil
+	case k == keySGRMouse:
+		if me := readSGRMouse(w); me != nil {
+			return me
+		}
+		return nil
+	case k == KEY_RESIZE:
+		rows, cols := StdScr().MaxYX()
+		return ResizeEvent{Rows: rows, Cols: cols}
+	case k == keyPasteStart:
+		return readPaste(w)
+	case k == keyPasteEnd:
+		return nil
+	case k != 0:
+		key, mod := DecodeKey(k)
+		return KeyEvent{Key: key, Modifiers: mod}
+	}
+	return KeyEvent{Key: Key(r), Rune: r}
+}
+
+// readAlt returns the event for an escape character. Terminals send a key
+// pressed with alt held as an escape followed by the key, so if another key
+// is already waiting it is returned with MOD_ALT set.
+func readAlt(w *Window) Event {
+	defer w.Timeout(w.Delay())
+	w.Timeout(0)
+	r, k, err := w.GetWChar()
+	switch {
+	case err != nil:
+		return KeyEvent{Key: KEY_ESC, Rune: KEY_ESC}
+	case k == KEY_MOUSE || k == keySGRMouse || k == KEY_RESIZE:
+		UnGetChar(Char(k))
+		return KeyEvent{Key: KEY_ESC, Rune: KEY_ESC}
+	case k != 0:
+		key, mod := DecodeKey(k)
+		return KeyEvent{Key: key, Modifiers: mod | MOD_ALT}
+	}
+	return KeyEvent{Key: Key(r), Rune: r, Modifiers: MOD_ALT}
+}
+
+// publish delivers ev to the application. It returns false if the loop was
+// stopped before the event could be delivered.
+func (l *EventLoop) publish(ev Event) bool {
+	select {
+	case l.events <- ev:
+		return true
+	case <-l.quit:
+		return false
+	}
+}
+
+// Timer delivers TimerEvents on its EventLoop's event channel
+type Timer struct {
+	loop    *EventLoop
+	d       time.Duration
+	repeat  bool
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+// Stop prevents the timer from firing again. Events already queued may
+// still be delivered.
+func (t *Timer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	t.timer.Stop()
+}
+
+func (t *Timer) fire() {
+	now := time.Now()
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	if t.repeat {
+		t.timer.Reset(t.d)
+	}
+	t.mu.Unlock()
+	select {
+	case t.loop.timers <- TimerEvent{Timer: t, Time: now}:
+	case <-t.loop.done:
+	}
+}
@@ -0,0 +1,50 @@
+package goncurses_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestEventLoop(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	loop := goncurses.NewEventLoop(w)
+	defer loop.Stop()
+
+	scr.Send("é\x1bOB\x1b[1;5D\x1b[1;2A\x1b[15;2~\x1bx")
+	expected := []goncurses.KeyEvent{
+		{Key: goncurses.Key('é'), Rune: 'é'},
+		{Key: goncurses.KEY_DOWN},
+		{Key: goncurses.KEY_LEFT, Modifiers: goncurses.MOD_CTRL},
+		{Key: goncurses.KEY_UP, Modifiers: goncurses.MOD_SHIFT},
+		{Key: goncurses.KEY_F5, Modifiers: goncurses.MOD_SHIFT},
+		{Key: 'x', Rune: 'x', Modifiers: goncurses.MOD_ALT},
+	}
+	for _, e := range expected {
+		if ev := <-loop.Events(); ev != e {
+			t.Errorf("got %#v; want %#v", ev, e)
+		}
+	}
+
+	timer := loop.NewTimer(time.Millisecond, false)
+	if ev, ok := (<-loop.Events()).(goncurses.TimerEvent); !ok ||
+		ev.Timer != timer {
+		t.Errorf("got %#v; want TimerEvent", ev)
+	}
+
+	drawn := make(chan struct{})
+	loop.Draw(func() {
+		w.MovePrint(0, 0, "drawn")
+		w.Refresh()
+		close(drawn)
+	})
+	<-drawn
+	if got := scr.Line(0); got[:5] != "drawn" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/* This example demonstrates using an EventLoop to receive input as events
+ * on a channel while all drawing happens on the event loop's goroutine */
+package main
+
+import (
+	"log"
+	"time"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+func main() {
+	stdscr, err := gc.Init()
+	if err != nil {
+		log.Fatal("init:", err)
+	}
+	defer gc.End()
+
+	gc.Echo(false)
+	gc.CBreak(true)
+	gc.Cursor(0)
+	stdscr.Keypad(true)
+	gc.MouseMask(gc.M_ALL, nil)
+
+	stdscr.Println("Type characters, click or resize the terminal.")
+	stdscr.Println("Press 'q' to exit.")
+	stdscr.Refresh()
+
+	loop := gc.NewEventLoop(stdscr)
+	defer loop.Stop()
+	loop.NewTimer(time.Second, true)
+
+	for ev := range loop.Events() {
+		switch e := ev.(type) {
+		case gc.KeyEvent:
+			if e.Rune == 'q' {
+				return
+			}
+			loop.Draw(func() {
+				stdscr.MovePrintf(3, 0, "Key: %s", gc.KeyString(e.Key))
+				stdscr.ClearToEOL()
+				stdscr.Refresh()
+			})
+		case *gc.MouseEvent:
+			loop.Draw(func() {
+				stdscr.MovePrintf(4, 0, "Mouse: %d, %d", e.Y, e.X)
+				stdscr.ClearToEOL()
+				stdscr.Refresh()
+			})
+		case gc.ResizeEvent:
+			loop.Draw(func() {
+				stdscr.MovePrintf(5, 0, "Size: %dx%d", e.Cols, e.Rows)
+				stdscr.ClearToEOL()
+				stdscr.Refresh()
+			})
+		case gc.TimerEvent:
+			loop.Draw(func() {
+				stdscr.MovePrint(6, 0, "Time: ", e.Time.Format("15:04:05"))
+				stdscr.Refresh()
+			})
+		}
+	}
+}
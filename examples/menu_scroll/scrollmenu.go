@@ -23,6 +23,7 @@ func main() {
 	gc.Echo(false)
 	gc.Cursor(0)
 	stdscr.Keypad(true)
+	gc.MouseMask(gc.M_ALL, nil)
 	gc.InitPair(1, gc.C_RED, gc.C_BLACK)
 	gc.InitPair(2, gc.C_CYAN, gc.C_BLACK)
 
@@ -71,7 +72,7 @@ func main() {
 	y, _ := stdscr.MaxYX()
 	stdscr.ColorOn(2)
 	stdscr.MovePrint(y-3, 1,
-		"Use up/down arrows or page up/down to navigate. 'q' to exit")
+		"Use the arrows, page up/down or the mouse to navigate. 'q' to exit")
 	stdscr.ColorOff(2)
 	stdscr.Refresh()
 
@@ -79,12 +80,18 @@ func main() {
 	defer menu.UnPost()
 	menuwin.Refresh()
 
+	keys := gc.NewMenuKeymap(nil)
 	for {
 		gc.Update()
-		if ch := menuwin.GetChar(); ch == 'q' {
+		switch ch := menuwin.GetChar(); ch {
+		case 'q':
 			return
-		} else {
-			menu.Driver(gc.DriverActions[ch])
+		case gc.KEY_MOUSE:
+			if me := gc.GetMouse(); me != nil {
+				menu.HandleMouse(me)
+			}
+		default:
+			menu.Drive(keys, gc.NewKeyEvent(ch))
 		}
 	}
 }
@@ -61,6 +61,7 @@ func main() {
 	menu.Post()
 	defer menu.UnPost()
 
+	keys := gc.NewMenuKeymap(nil)
 	for {
 		gc.Update()
 		ch := stdscr.GetChar()
@@ -75,7 +76,7 @@ func main() {
 			stdscr.Printf("Item selected is: %s", menu.Current(nil).Name())
 			menu.PositionCursor()
 		default:
-			menu.Driver(gc.DriverActions[ch])
+			menu.Drive(keys, gc.NewKeyEvent(ch))
 		}
 	}
 }
@@ -49,6 +49,7 @@ func main() {
 	menu.Post()
 	defer menu.UnPost()
 
+	keys := gc.NewMenuKeymap(nil)
 	for {
 		gc.Update()
 		ch := stdscr.GetChar()
@@ -70,7 +71,7 @@ func main() {
 			stdscr.MovePrint(20, 0, list)
 			stdscr.Refresh()
 		default:
-			menu.Driver(gc.DriverActions[ch])
+			menu.Drive(keys, gc.NewKeyEvent(ch))
 		}
 	}
 }
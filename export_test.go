@@ -0,0 +1,17 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import "math"
+
+// SetNative chooses between ncurses' pair allocation and the palette's own,
+// so that tests can cover both whichever the ncurses version
+func (p *Palette) SetNative(on bool) {
+	p.native = on && hasAllocPair()
+	if !p.native && p.limit > math.MaxInt16 {
+		p.limit = math.MaxInt16
+	}
+}
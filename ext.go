@@ -18,6 +18,9 @@ import (
 
 // DriverActions is a convenience mapping for common responses
 // to keyboard input
+//
+// Deprecated: DriverActions can not be changed per menu and does not
+// support modifiers or key sequences. Use NewMenuKeymap and Menu.Drive.
 var DriverActions = map[Key]MenuDriverReq{
 	KEY_DOWN:     C.REQ_DOWN_ITEM,
 	KEY_HOME:     C.REQ_FIRST_ITEM,
@@ -29,6 +32,152 @@ var DriverActions = map[Key]MenuDriverReq{
 	KEY_UP:       C.REQ_UP_ITEM,
 }
 
+// MenuActions maps the names of actions which may be bound in a Keymap to
+// the menu driver requests performed by Menu.Drive. The names are those of
+// the REQ_* menu requests in lower case, with hyphens for underscores.
+var MenuActions = map[string]MenuDriverReq{
+	"left":          REQ_LEFT,
+	"right":         REQ_RIGHT,
+	"up":            REQ_UP,
+	"down":          REQ_DOWN,
+	"uline":         REQ_ULINE,
+	"dline":         REQ_DLINE,
+	"page-down":     REQ_PAGE_DOWN,
+	"page-up":       REQ_PAGE_UP,
+	"first":         REQ_FIRST,
+	"last":          REQ_LAST,
+	"next":          REQ_NEXT,
+	"prev":          REQ_PREV,
+	"toggle":        REQ_TOGGLE,
+	"clear-pattern": REQ_CLEAR_PATTERN,
+	"back-pattern":  REQ_BACK_PATTERN,
+	"next-match":    REQ_NEXT_MATCH,
+	"prev-match":    REQ_PREV_MATCH,
+}
+
+// FormActions maps the names of actions which may be bound in a Keymap to
+// the form driver requests performed by Form.Drive. The names are those of
+// the REQ_* form requests in lower case, with hyphens for underscores.
+var FormActions = map[string]FormDriverReq{
+	"next-page":    REQ_NEXT_PAGE,
+	"prev-page":    REQ_PREV_PAGE,
+	"first-page":   REQ_FIRST_PAGE,
+	"last-page":    REQ_LAST_PAGE,
+	"next-field":   REQ_NEXT_FIELD,
+	"prev-field":   REQ_PREV_FIELD,
+	"first-field":  REQ_FIRST_FIELD,
+	"last-field":   REQ_LAST_FIELD,
+	"snext-field":  REQ_SNEXT_FIELD,
+	"sprev-field":  REQ_SPREV_FIELD,
+	"sfirst-field": REQ_SFIRST_FIELD,
+	"slast-field":  REQ_SLAST_FIELD,
+	"left-field":   REQ_LEFT_FIELD,
+	"right-field":  REQ_RIGHT_FIELD,
+	"up-field":     REQ_UP_FIELD,
+	"down-field":   REQ_DOWN_FIELD,
+	"next-char":    REQ_NEXT_CHAR,
+	"prev-char":    REQ_PREV_CHAR,
+	"next-line":    REQ_NEXT_LINE,
+	"prev-line":    REQ_PREV_LINE,
+	"next-word":    REQ_NEXT_WORD,
+	"prev-word":    REQ_PREV_WORD,
+	"beg-field":    REQ_BEG_FIELD,
+	"end-field":    REQ_END_FIELD,
+	"beg-line":     REQ_BEG_LINE,
+	"end-line":     REQ_END_LINE,
+	"left-char":    REQ_LEFT_CHAR,
+	"right-char":   REQ_RIGHT_CHAR,
+	"up-char":      REQ_UP_CHAR,
+	"down-char":    REQ_DOWN_CHAR,
+	"new-line":     REQ_NEW_LINE,
+	"ins-char":     REQ_INS_CHAR,
+	"ins-line":     REQ_INS_LINE,
+	"del-char":     REQ_DEL_CHAR,
+	"del-prev":     REQ_DEL_PREV,
+	"del-line":     REQ_DEL_LINE,
+	"del-word":     REQ_DEL_WORD,
+	"clr-eol":      REQ_CLR_EOL,
+	"clr-eof":      REQ_CLR_EOF,
+	"clr-field":    REQ_CLR_FIELD,
+	"ovl-mode":     REQ_OVL_MODE,
+	"ins-mode":     REQ_INS_MODE,
+	"scr-fline":    REQ_SCR_FLINE,
+	"scr-bline":    REQ_SCR_BLINE,
+	"scr-fpage":    REQ_SCR_FPAGE,
+	"scr-bpage":    REQ_SCR_BPAGE,
+	"scr-fhpage":   REQ_SCR_FHPAGE,
+	"scr-bhpage":   REQ_SCR_BHPAGE,
+	"scr-fchar":    REQ_SCR_FCHAR,
+	"scr-bchar":    REQ_SCR_BCHAR,
+	"scr-hfline":   REQ_SCR_HFLINE,
+	"scr-hbline":   REQ_SCR_HBLINE,
+	"scr-hfhalf":   REQ_SCR_HFHALF,
+	"scr-hbhalf":   REQ_SCR_HBHALF,
+	"validation":   REQ_VALIDATION,
+	"next-choice":  REQ_NEXT_CHOICE,
+	"prev-choice":  REQ_PREV_CHOICE,
+}
+
+// NewMenuKeymap returns a keymap layered over parent, which may be nil,
+// binding the cursor keys, home, end and page up/down to the corresponding
+// MenuActions
+func NewMenuKeymap(parent *Keymap) *Keymap {
+	return newDefaultKeymap(parent, map[string]string{
+		"up":          "up",
+		"down":        "down",
+		"left":        "left",
+		"right":       "right",
+		"home":        "first",
+		"end":         "last",
+		"<page up>":   "page-up",
+		"<page down>": "page-down",
+	})
+}
+
+// NewFormKeymap returns a keymap layered over parent, which may be nil,
+// binding tab and shift-tab, the cursor keys, home, end, enter, backspace
+// and delete to the corresponding FormActions
+func NewFormKeymap(parent *Keymap) *Keymap {
+	return newDefaultKeymap(parent, map[string]string{
+		"tab":       "next-field",
+		"S-tab":     "prev-field",
+		"down":      "next-field",
+		"up":        "prev-field",
+		"left":      "prev-char",
+		"right":     "next-char",
+		"home":      "beg-field",
+		"end":       "end-field",
+		"enter":     "new-line",
+		"backspace": "del-prev",
+		"C-?":       "del-prev",
+		"DC":        "del-char",
+	})
+}
+
+// NewWizardKeymap returns a keymap for a Wizard layered over parent, which
+// may be nil. It adds to the bindings of NewFormKeymap page up and alt-b
+// for the "back" action, page down and alt-n for "next", and alt-f for
+// "finish".
+func NewWizardKeymap(parent *Keymap) *Keymap {
+	return newDefaultKeymap(NewFormKeymap(parent), map[string]string{
+		"<page up>":   "back",
+		"M-b":         "back",
+		"<page down>": "next",
+		"M-n":         "next",
+		"M-f":         "finish",
+	})
+}
+
+func newDefaultKeymap(parent *Keymap, bindings map[string]string) *Keymap {
+	km := NewKeymap(parent)
+	for seq, action := range bindings {
+		if err := km.Bind(seq, action); err != nil {
+			panic(err)
+		}
+	}
+	return km
+}
+
 var errList = map[C.int]string{
 	C.E_SYSTEM_ERROR:    "System error occurred",
 	C.E_BAD_ARGUMENT:    "Incorrect or out-of-range argument",
@@ -0,0 +1,297 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+/*
+#include <form.h>
+#include <stdarg.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+// set_field_type takes a variable number of arguments, which cgo can not
+// pass, so each type has its own wrapper
+
+static int goncurses_field_alnum(FIELD *f, int width) {
+	return set_field_type(f, TYPE_ALNUM, width);
+}
+
+static int goncurses_field_alpha(FIELD *f, int width) {
+	return set_field_type(f, TYPE_ALPHA, width);
+}
+
+static int goncurses_field_enum(FIELD *f, char **values, int cs, int unique) {
+	return set_field_type(f, TYPE_ENUM, values, cs, unique);
+}
+
+static int goncurses_field_integer(FIELD *f, int pad, long min, long max) {
+	return set_field_type(f, TYPE_INTEGER, pad, min, max);
+}
+
+static int goncurses_field_ipv4(FIELD *f) {
+	return set_field_type(f, TYPE_IPV4);
+}
+
+static int goncurses_field_numeric(FIELD *f, int pad, double min, double max) {
+	return set_field_type(f, TYPE_NUMERIC, pad, min, max);
+}
+
+static int goncurses_field_regexp(FIELD *f, char *re) {
+	return set_field_type(f, TYPE_REGEXP, re);
+}
+
+static int goncurses_field_none(FIELD *f) {
+	return set_field_type(f, NULL);
+}
+
+// Field types implemented in Go are given the id by which the Go side knows
+// them as their argument. See callbacks.go.
+
+extern int goncursesCheckField(FIELD *f, uintptr_t id);
+extern int goncursesCheckChar(int c, uintptr_t id);
+extern int goncursesNextChoice(FIELD *f, uintptr_t id);
+extern int goncursesPrevChoice(FIELD *f, uintptr_t id);
+
+static bool goncurses_check_field(FIELD *f, const void *arg) {
+	return goncursesCheckField(f, (uintptr_t)arg);
+}
+
+static bool goncurses_check_char(int c, const void *arg) {
+	return goncursesCheckChar(c, (uintptr_t)arg);
+}
+
+static bool goncurses_next_choice(FIELD *f, const void *arg) {
+	return goncursesNextChoice(f, (uintptr_t)arg);
+}
+
+static bool goncurses_prev_choice(FIELD *f, const void *arg) {
+	return goncursesPrevChoice(f, (uintptr_t)arg);
+}
+
+static void *goncurses_make_arg(va_list *ap) {
+	return (void *)va_arg(*ap, uintptr_t);
+}
+
+static void *goncurses_copy_arg(const void *arg) {
+	return (void *)arg;
+}
+
+static void goncurses_free_arg(void *arg) {
+}
+
+static FIELDTYPE *goncurses_new_fieldtype(int field, int ch) {
+	FIELDTYPE *t = new_fieldtype(field ? goncurses_check_field : NULL,
+		ch ? goncurses_check_char : NULL);
+	if (t != NULL && set_fieldtype_arg(t, goncurses_make_arg,
+			goncurses_copy_arg, goncurses_free_arg) != E_OK) {
+		free_fieldtype(t);
+		return NULL;
+	}
+	return t;
+}
+
+static int goncurses_fieldtype_choice(FIELDTYPE *t) {
+	return set_fieldtype_choice(t, goncurses_next_choice,
+		goncurses_prev_choice);
+}
+
+static int goncurses_field_custom(FIELD *f, FIELDTYPE *t, uintptr_t id) {
+	return set_field_type(f, t, id);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// FieldType is the kind of data a field accepts. Once set on a field with
+// SetType, the form driver refuses characters the type does not allow and
+// refuses to leave the field, returning an error, until its contents are
+// valid. Validation may also be requested with REQ_VALIDATION. A field with
+// FO_PASSOK set, as it is by default, is only validated once it has been
+// edited.
+type FieldType struct {
+	set func(f *C.FIELD) C.int
+
+	// types implemented in Go
+	id            uintptr
+	ctype         *C.FIELDTYPE
+	validateField func(*Field) bool
+	validateChar  func(rune) bool
+	next, prev    func(*Field) bool
+}
+
+// fieldTypes are the field types implemented in Go, by id
+var (
+	fieldTypes  = make(map[uintptr]*FieldType)
+	fieldTypeID uintptr
+)
+
+// NewFieldType creates a field type implemented in Go. When a field of the
+// type is validated, validateField is called to check its contents, which
+// may be read with Buffer. Each character typed into the field is first
+// passed to validateChar and refused if it returns false. Either function
+// may be nil, but not both. The type should be freed with Free once no
+// field uses it.
+func NewFieldType(validateField func(*Field) bool,
+	validateChar func(rune) bool) (*FieldType, error) {
+	if validateField == nil && validateChar == nil {
+		return nil, errors.New("Field type needs a validation function")
+	}
+	ctype, err := C.goncurses_new_fieldtype(cbool(validateField != nil),
+		cbool(validateChar != nil))
+	if ctype == nil {
+		if err == nil {
+			err = errors.New("Failed to create field type")
+		}
+		return nil, ncursesError(err)
+	}
+	fieldTypeID++
+	t := &FieldType{
+		id:            fieldTypeID,
+		ctype:         ctype,
+		validateField: validateField,
+		validateChar:  validateChar,
+	}
+	t.set = func(f *C.FIELD) C.int {
+		return C.goncurses_field_custom(f, t.ctype, C.uintptr_t(t.id))
+	}
+	fieldTypes[t.id] = t
+	return t, nil
+}
+
+// SetChoices sets the functions called for REQ_NEXT_CHOICE and
+// REQ_PREV_CHOICE on a field of a type created by NewFieldType. They should
+// replace the contents of the field, using SetBuffer, with the next or
+// previous of the values it may take and return true, or return false if
+// there is none.
+func (t *FieldType) SetChoices(next, prev func(*Field) bool) error {
+	if t.ctype == nil {
+		return errors.New("Choices can only be set on types created by " +
+			"NewFieldType")
+	}
+	if next == nil || prev == nil {
+		return errors.New("Both choice functions must be given")
+	}
+	t.next, t.prev = next, prev
+	err := C.goncurses_fieldtype_choice(t.ctype)
+	return ncursesError(syscall.Errno(err))
+}
+
+// Free releases a field type created by NewFieldType. It fails if a field
+// still uses the type. Freeing any other type does nothing.
+func (t *FieldType) Free() error {
+	if t.ctype == nil {
+		return nil
+	}
+	err := C.free_fieldtype(t.ctype)
+	if err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	delete(fieldTypes, t.id)
+	t.ctype = nil
+	return nil
+}
+
+// AlnumType accepts letters and digits. At least width characters must be
+// entered.
+func AlnumType(width int) *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		return C.goncurses_field_alnum(f, C.int(width))
+	}}
+}
+
+// AlphaType accepts letters. At least width characters must be entered.
+func AlphaType(width int) *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		return C.goncurses_field_alpha(f, C.int(width))
+	}}
+}
+
+// EnumType accepts one of the values. A prefix of a value is completed to
+// the whole value when the field is validated, unless unique is true and
+// the prefix matches more than one value. REQ_NEXT_CHOICE and
+// REQ_PREV_CHOICE step through the values.
+func EnumType(values []string, caseSensitive, unique bool) *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		cvalues := make([]*C.char, len(values)+1)
+		for i, v := range values {
+			cvalues[i] = C.CString(v)
+			defer C.free(unsafe.Pointer(cvalues[i]))
+		}
+		// the list may not be kept in Go memory, ncurses copies it
+		list := (**C.char)(C.malloc(C.size_t(len(cvalues)) *
+			C.size_t(unsafe.Sizeof(cvalues[0]))))
+		defer C.free(unsafe.Pointer(list))
+		copy((*[1 << 20]*C.char)(unsafe.Pointer(list))[:len(cvalues)],
+			cvalues)
+		return C.goncurses_field_enum(f, list, cbool(caseSensitive),
+			cbool(unique))
+	}}
+}
+
+// IntegerType accepts an integer, optionally preceded by a minus sign,
+// between min and max. If min and max are both zero (0) any integer is
+// accepted. The value is padded with leading zeros to pad digits when
+// validated.
+func IntegerType(pad int, min, max int) *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		return C.goncurses_field_integer(f, C.int(pad), C.long(min),
+			C.long(max))
+	}}
+}
+
+// IPv4Type accepts an IP version 4 address, such as 192.168.0.1
+func IPv4Type() *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		return C.goncurses_field_ipv4(f)
+	}}
+}
+
+// NumericType accepts a decimal number between min and max. If min and max
+// are both zero (0) any number is accepted. The value is shown with pad
+// digits after the decimal point when validated.
+func NumericType(pad int, min, max float64) *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		return C.goncurses_field_numeric(f, C.int(pad), C.double(min),
+			C.double(max))
+	}}
+}
+
+// RegexpType accepts text matching the POSIX extended regular expression
+// re. The expression is matched against the whole of the field's buffer,
+// including any trailing blanks, so it should usually allow for them, as
+// in "^[0-9]+ *$".
+func RegexpType(re string) *FieldType {
+	return &FieldType{set: func(f *C.FIELD) C.int {
+		cre := C.CString(re)
+		defer C.free(unsafe.Pointer(cre))
+		return C.goncurses_field_regexp(f, cre)
+	}}
+}
+
+// SetType sets the type of data the field accepts. Passing nil removes the
+// field's type, so that it accepts any data.
+func (f *Field) SetType(t *FieldType) error {
+	var err C.int
+	if t == nil {
+		err = C.goncurses_field_none((*C.FIELD)(f))
+	} else {
+		err = t.set((*C.FIELD)(f))
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+func cbool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
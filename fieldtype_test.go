@@ -0,0 +1,134 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestFieldType(t *testing.T) {
+	scr := newScreen(t, 5, 40)
+	defer scr.End()
+
+	tests := []struct {
+		typ      *goncurses.FieldType
+		value    string
+		valid    bool
+		expected string
+	}{
+		{goncurses.IntegerType(3, 0, 0), "42", true, "042"},
+		{goncurses.IntegerType(0, 1, 10), "42", false, "42"},
+		{goncurses.IntegerType(0, 0, 0), "4x", false, "4x"},
+		{goncurses.NumericType(2, 0, 100), "3.5", true, "3.50"},
+		{goncurses.RegexpType("^[a-f]+ *$"), "face", true, "face"},
+		{goncurses.RegexpType("^[a-f]+ *$"), "fake", false, "fake"},
+		{goncurses.AlnumType(3), "ab1", true, "ab1"},
+		{goncurses.AlnumType(3), "a-1", false, "a-1"},
+		{goncurses.AlphaType(1), "ab1", false, "ab1"},
+		{goncurses.EnumType([]string{"red", "green"}, false, true), "GR",
+			true, "green"},
+		{goncurses.EnumType([]string{"red", "green"}, true, true), "GR",
+			false, "GR"},
+		{goncurses.IPv4Type(), "10.0.0.1", true, "10.0.0.1"},
+		{goncurses.IPv4Type(), "10.0.0.256", false, "10.0.0.256"},
+		{nil, "anything", true, "anything"},
+	}
+	for _, test := range tests {
+		field, _ := goncurses.NewField(1, 12, 0, 0, 0, 0)
+		if err := field.SetType(test.typ); err != nil {
+			t.Fatal(err)
+		}
+		// the buffer is not typed, so only validated without FO_PASSOK
+		field.Options(goncurses.FO_PASSOK, false)
+		form, _ := goncurses.NewForm([]*goncurses.Field{field})
+		form.Post()
+		field.SetBuffer(test.value)
+		err := form.Driver(goncurses.REQ_VALIDATION)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("%q: got valid %v; want %v", test.value, valid,
+				test.valid)
+		}
+		if s := strings.TrimSpace(field.Buffer()); s != test.expected {
+			t.Errorf("%q: got %q after validation; want %q", test.value, s,
+				test.expected)
+		}
+		form.UnPost()
+		form.Free()
+		field.Free()
+	}
+}
+
+func TestNewFieldType(t *testing.T) {
+	scr := newScreen(t, 5, 40)
+	defer scr.End()
+
+	colors := []string{"red", "green", "blue"}
+	index := func(f *goncurses.Field) int {
+		for i, c := range colors {
+			if c == strings.TrimSpace(f.Buffer()) {
+				return i
+			}
+		}
+		return -1
+	}
+	typ, err := goncurses.NewFieldType(
+		func(f *goncurses.Field) bool { return index(f) >= 0 },
+		func(r rune) bool { return r >= 'a' && r <= 'z' })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer typ.Free()
+	step := func(n int) func(*goncurses.Field) bool {
+		return func(f *goncurses.Field) bool {
+			i := (index(f) + n + len(colors)) % len(colors)
+			return f.SetBuffer(colors[i]) == nil
+		}
+	}
+	if err := typ.SetChoices(step(1), step(-1)); err != nil {
+		t.Fatal(err)
+	}
+
+	field, _ := goncurses.NewField(1, 10, 0, 0, 0, 0)
+	defer field.Free()
+	if err := field.SetType(typ); err != nil {
+		t.Fatal(err)
+	}
+	form, _ := goncurses.NewForm([]*goncurses.Field{field})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+
+	for _, r := range "b1LUE" {
+		form.Driver(goncurses.Key(r))
+	}
+	if err := form.Driver(goncurses.REQ_VALIDATION); err == nil {
+		t.Errorf("expected %q to be invalid", field.Buffer())
+	}
+	form.Driver(goncurses.REQ_CLR_FIELD)
+	for _, r := range "blue" {
+		form.Driver(goncurses.Key(r))
+	}
+	if err := form.Driver(goncurses.REQ_VALIDATION); err != nil {
+		t.Errorf("%q: %v", field.Buffer(), err)
+	}
+	form.Driver(goncurses.REQ_NEXT_CHOICE)
+	if s := strings.TrimSpace(field.Buffer()); s != "red" {
+		t.Errorf("got %q after next choice; want \"red\"", s)
+	}
+	form.Driver(goncurses.REQ_PREV_CHOICE)
+	form.Driver(goncurses.REQ_PREV_CHOICE)
+	if s := strings.TrimSpace(field.Buffer()); s != "green" {
+		t.Errorf("got %q after previous choices; want \"green\"", s)
+	}
+	if err := typ.Free(); err == nil {
+		t.Error("expected error freeing a type in use")
+	}
+}
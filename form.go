@@ -6,19 +6,79 @@
 
 package goncurses
 
-// #cgo !darwin,!openbsd pkg-config: form
-// #cgo darwin openbsd LDFLAGS: -lform
-// #include <form.h>
-// #include <stdlib.h>
+/*
+#cgo !darwin,!openbsd pkg-config: formw
+#cgo darwin openbsd LDFLAGS: -lform
+#include <form.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+// The hooks of a form are found by the id stored as its user pointer. See
+// callbacks.go.
+
+extern void goncursesFormHook(uintptr_t id, int hook);
+
+static void goncurses_field_init(FORM *f) {
+	goncursesFormHook((uintptr_t)form_userptr(f), 0);
+}
+
+static void goncurses_field_term(FORM *f) {
+	goncursesFormHook((uintptr_t)form_userptr(f), 1);
+}
+
+static void goncurses_form_init(FORM *f) {
+	goncursesFormHook((uintptr_t)form_userptr(f), 2);
+}
+
+static void goncurses_form_term(FORM *f) {
+	goncursesFormHook((uintptr_t)form_userptr(f), 3);
+}
+
+static int goncurses_set_form_hook(FORM *f, uintptr_t id, int hook, int on) {
+	if (set_form_userptr(f, (void *)id) != E_OK)
+		return E_SYSTEM_ERROR;
+	switch (hook) {
+	case 0:
+		return set_field_init(f, on ? goncurses_field_init : NULL);
+	case 1:
+		return set_field_term(f, on ? goncurses_field_term : NULL);
+	case 2:
+		return set_form_init(f, on ? goncurses_form_init : NULL);
+	case 3:
+		return set_form_term(f, on ? goncurses_form_term : NULL);
+	}
+	return E_BAD_ARGUMENT;
+}
+*/
 import "C"
 
 import (
+	"errors"
+	"strings"
 	"syscall"
+	"unicode"
+	"unicode/utf8"
 	"unsafe"
 )
 
 type Field C.FIELD
 
+// fieldData is the application data associated with fields by SetUserData
+var fieldData = make(map[*Field]interface{})
+
+// fieldOrigins are the fields created by Link, with the field whose buffer
+// they share. The wide character library may crash reading the buffer of a
+// linked field, so it is read from the original.
+var fieldOrigins = make(map[*Field]*Field)
+
+// origin returns the field f was linked from, or f
+func (f *Field) origin() *Field {
+	if o, ok := fieldOrigins[f]; ok {
+		return o
+	}
+	return f
+}
+
 type Form struct {
 	form *C.FORM
 }
@@ -36,9 +96,10 @@ func (f *Field) Background() Char {
 
 // Buffer returns a string containing the contents of the buffer. The returned
 // string will contain whitespace up to the buffer size as set by SetMax or
-// the value by the call to NewField
+// the value by the call to NewField. The buffer of a field created by Link
+// is read from the field it was linked from.
 func (f *Field) Buffer() string {
-	str := C.field_buffer((*C.FIELD)(f), C.int(0))
+	str := C.field_buffer((*C.FIELD)(f.origin()), C.int(0))
 
 	return C.GoString(str)
 }
@@ -47,31 +108,66 @@ func (f *Field) Buffer() string {
 // to the newly allocated object.
 func (f *Field) Duplicate(y, x int32) (*Field, error) {
 	nf, err := C.dup_field((*C.FIELD)(f), C.int(y), C.int(x))
+	if nf != nil {
+		if data, ok := fieldData[f]; ok {
+			fieldData[(*Field)(nf)] = data
+		}
+	}
 	return (*Field)(nf), ncursesError(err)
 }
 
+// Dynamic returns true if the field grows as text is entered, see SetDynamic
+func (f *Field) Dynamic() bool {
+	return C.field_opts((*C.FIELD)(f))&C.O_STATIC == 0
+}
+
+// DynamicInfo returns the number of rows and columns of a dynamic field's
+// buffer, which grow as text is entered, and the maximum size it may grow
+// to, or zero (0) if there is no limit. See SetMax.
+func (f *Field) DynamicInfo() (rows, cols, max int, err error) {
+	var r, c, m C.int
+	e := C.dynamic_field_info((*C.FIELD)(f), &r, &c, &m)
+	return int(r), int(c), int(m), ncursesError(syscall.Errno(e))
+}
+
 // Foreground returns the field's foreground character attributes
 func (f *Field) Foreground() Char {
 	return Char(C.field_fore((*C.FIELD)(f)))
 }
 
 // Free field's allocated memory. This must be called to prevent memory
-// leaks
+// leaks. A field may not be freed before the fields linked to it.
 func (f *Field) Free() error {
+	for _, o := range fieldOrigins {
+		if o == f {
+			return errors.New("Field has linked fields")
+		}
+	}
 	err := C.free_field((*C.FIELD)(f))
+	if err == C.E_OK {
+		delete(fieldData, f)
+		delete(fieldOrigins, f)
+		if m := fieldMasks[f]; m != nil {
+			delete(fieldMasks, f)
+			m.typ.Free()
+		}
+	}
 	f = nil
 	return ncursesError(syscall.Errno(err))
 }
 
-// Info retrieves the height, width, y, x, offset and buffer size of the
-// given field. Pass the memory address of the variable to store the data
-// in or nil.
-func (f *Field) Info(h, w, y, x, off, nbuf *int) error {
-	err := C.field_info((*C.FIELD)(f), (*C.int)(unsafe.Pointer(h)),
-		(*C.int)(unsafe.Pointer(w)), (*C.int)(unsafe.Pointer(y)),
-		(*C.int)(unsafe.Pointer(x)), (*C.int)(unsafe.Pointer(off)),
-		(*C.int)(unsafe.Pointer(nbuf)))
-	return ncursesError(syscall.Errno(err))
+// Index returns the position of the field in its form's list of fields
+func (f *Field) Index() int {
+	return int(C.field_index((*C.FIELD)(f)))
+}
+
+// Info returns the height, width, y, x, number of offscreen rows and number
+// of additional buffers of the field
+func (f *Field) Info() (h, w, y, x, off, nbuf int, err error) {
+	var ch, cw, cy, cx, coff, cnbuf C.int
+	e := C.field_info((*C.FIELD)(f), &ch, &cw, &cy, &cx, &coff, &cnbuf)
+	return int(ch), int(cw), int(cy), int(cx), int(coff), int(cnbuf),
+		ncursesError(syscall.Errno(e))
 }
 
 // Just returns the justification type of the field
@@ -79,13 +175,28 @@ func (f *Field) Justification() int {
 	return int(C.field_just((*C.FIELD)(f)))
 }
 
+// Link returns a new field at the specified coordinates which shares the
+// buffer of this one, so that changes to either are seen in both. The
+// field's user data is shared as well. The original field must be freed
+// after the fields linked to it.
+func (f *Field) Link(y, x int32) (*Field, error) {
+	nf, err := C.link_field((*C.FIELD)(f), C.int(y), C.int(x))
+	if nf != nil {
+		if data, ok := fieldData[f]; ok {
+			fieldData[(*Field)(nf)] = data
+		}
+		fieldOrigins[(*Field)(nf)] = f.origin()
+	}
+	return (*Field)(nf), ncursesError(err)
+}
+
 // Move the field to the location of the specified coordinates
 func (f *Field) Move(y, x int32) error {
 	err := C.move_field((*C.FIELD)(f), C.int(y), C.int(x))
 	return ncursesError(syscall.Errno(err))
 }
 
-// Options turns features on and off
+// Options turns features on and off. The options set may be read with Opts.
 func (f *Field) Options(opts int, on bool) {
 	if on {
 		C.field_opts_on((*C.FIELD)(f), C.Field_Options(opts))
@@ -94,6 +205,12 @@ func (f *Field) Options(opts int, on bool) {
 	C.field_opts_off((*C.FIELD)(f), C.Field_Options(opts))
 }
 
+// Opts returns the options set on the field, such as FO_ACTIVE. It is the
+// getter for the options; Options can not be one as it already sets them.
+func (f *Field) Opts() int {
+	return int(C.field_opts((*C.FIELD)(f)))
+}
+
 // Pad returns the padding character of the field
 func (f *Field) Pad() int {
 	return int(C.field_pad((*C.FIELD)(f)))
@@ -109,6 +226,21 @@ func (f *Field) SetBuffer(s string) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetDynamic sets whether the field grows as text is entered, rather than
+// being limited to its size. A field of one line grows to the right and
+// scrolls horizontally, while one of more lines gains lines and scrolls
+// vertically. The growth may be limited with SetMax. Fields are static by
+// default.
+func (f *Field) SetDynamic(on bool) error {
+	var err C.int
+	if on {
+		err = C.field_opts_off((*C.FIELD)(f), C.O_STATIC)
+	} else {
+		err = C.field_opts_on((*C.FIELD)(f), C.O_STATIC)
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetJustification of the field
 func (f *Field) SetJustification(just int) error {
 	err := C.set_field_just((*C.FIELD)(f), C.int(just))
@@ -139,12 +271,86 @@ func (f *Field) SetOptionsOn(opts Char) error {
 	return nil
 }
 
+// NewPage returns true if the field starts a new page of its form
+func (f *Field) NewPage() bool {
+	return bool(C.new_page((*C.FIELD)(f)))
+}
+
+// SetNewPage sets whether the field starts a new page of the form. It must
+// be set before the field is connected to a form. The first field always
+// starts the first page.
+func (f *Field) SetNewPage(on bool) error {
+	err := C.set_new_page((*C.FIELD)(f), C.bool(on))
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetOpts sets the field's options, turning off any not included in opts
+func (f *Field) SetOpts(opts int) error {
+	err := C.set_field_opts((*C.FIELD)(f), C.Field_Options(opts))
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetPad sets the padding character of the field
 func (f *Field) SetPad(padch int) error {
 	err := C.set_field_pad((*C.FIELD)(f), C.int(padch))
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetStatus sets or clears the field's changed status, see Status
+func (f *Field) SetStatus(changed bool) error {
+	err := C.set_field_status((*C.FIELD)(f), C.bool(changed))
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetUserData associates a value of the application's with the field, such
+// as the object the field edits, which may be retrieved with UserData
+func (f *Field) SetUserData(data interface{}) {
+	if data == nil {
+		delete(fieldData, f)
+		return
+	}
+	fieldData[f] = data
+}
+
+// Status returns true if the field has been changed since its status was
+// last cleared with SetStatus. The status of the current field is only
+// updated when it is validated or left.
+func (f *Field) Status() bool {
+	return bool(C.field_status((*C.FIELD)(f)))
+}
+
+// Text returns the text entered in the field. Unlike Buffer, the blanks
+// padding each line, and any blank lines at the end, are removed, and the
+// lines of a field of more than one line are separated by newlines. Lines
+// scrolled out of view, or added as a dynamic field grew, are included. As
+// with Buffer, changes to the current field are only seen once it has been
+// validated or left.
+func (f *Field) Text() string {
+	rows, cols, _, err := f.DynamicInfo()
+	buf := []rune(f.Buffer())
+	if err != nil || rows < 2 || cols < 1 {
+		return strings.TrimRight(string(buf), " ")
+	}
+	lines := make([]string, 0, rows)
+	for len(buf) > 0 {
+		n := cols
+		if n > len(buf) {
+			n = len(buf)
+		}
+		lines = append(lines, strings.TrimRight(string(buf[:n]), " "))
+		buf = buf[n:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// UserData returns the value set with SetUserData, or nil
+func (f *Field) UserData() interface{} {
+	return fieldData[f]
+}
+
 // SetBackground character and attributes (colours, etc)
 func (f *Field) SetBackground(ch Char) error {
 	err := C.set_field_back((*C.FIELD)(f), C.chtype(ch))
@@ -170,12 +376,84 @@ func NewForm(fields []*Field) (Form, error) {
 
 // FieldCount returns the number of fields attached to the Form
 func (f *Form) FieldCount() int {
+	checkDispatch()
 	return int(C.field_count(f.form))
 }
 
+// Current returns the form's current field
+func (f *Form) Current() *Field {
+	checkDispatch()
+	return (*Field)(C.current_field(f.form))
+}
+
+// Drive feeds the key event to the keymap and, if the keys typed are bound
+// to one of the FormActions, performs the corresponding request. The action
+// is returned so that the application can handle any other actions itself.
+// Printable characters which are not bound are entered into the current
+// field, following its mask if it has one, see Field.SetMask.
+func (f *Form) Drive(km *Keymap, ev KeyEvent) (string, error) {
+	checkDispatch()
+	action, pending := km.Feed(ev)
+	if m := fieldMasks[(*Field)(C.current_field(f.form))]; m != nil &&
+		!pending {
+		if handled, err := m.drive(f, action, ev); handled {
+			return action, err
+		}
+	}
+	if req, ok := FormActions[action]; ok {
+		return action, f.edit(req)
+	}
+	if action == "" && !pending && ev.Modifiers == 0 && ev.Rune != 0 &&
+		unicode.IsPrint(ev.Rune) {
+		err := C.form_driver_w(f.form, C.OK, C.wchar_t(ev.Rune))
+		return "", ncursesError(syscall.Errno(err))
+	}
+	return action, nil
+}
+
+// edit issues the request, except that in a field of more than one line
+// REQ_NEW_LINE on the last line and REQ_DEL_PREV at the start are refused
+// rather than moving to another field, as the form's O_NL_OVERLOAD and
+// O_BS_OVERLOAD options would. REQ_DEL_PREV at the start of any other line
+// which can not be joined to the previous one moves to the end of that line.
+func (f *Form) edit(req FormDriverReq) error {
+	field := (*Field)(C.current_field(f.form))
+	if field == nil || req != REQ_NEW_LINE && req != REQ_DEL_PREV {
+		return f.Driver(Key(req))
+	}
+	if rows, _, _, err := field.DynamicInfo(); err != nil || rows < 2 {
+		return f.Driver(Key(req))
+	}
+	// without the overload options the form refuses the requests at the end
+	// and start of the field
+	opts := C.form_opts(f.form)
+	C.form_opts_off(f.form, C.O_NL_OVERLOAD|C.O_BS_OVERLOAD)
+	defer C.set_form_opts(f.form, opts)
+	_, col := f.cursor()
+	err := f.Driver(Key(req))
+	if err != nil && req == REQ_DEL_PREV && col == 0 &&
+		C.form_driver(f.form, C.REQ_UP_CHAR) == C.E_OK {
+		// the line is too long to join, or the form is in overlay mode
+		return f.Driver(REQ_END_LINE)
+	}
+	return err
+}
+
+// cursor returns the position of the cursor relative to the current field.
+// It is also the position in the field's text unless the field has been
+// scrolled, which fields of more than one line only are vertically.
+func (f *Form) cursor() (int, int) {
+	C.pos_form_cursor(f.form)
+	_, _, top, left, _, _, _ := (*Field)(C.current_field(f.form)).Info()
+	sub := Window{C.form_sub(f.form)}
+	y, x := sub.CursorYX()
+	return y - top, x - left
+}
+
 // Driver issues the actions requested to the form itself. See the
 // corresponding REQ_* constants
 func (f *Form) Driver(drvract Key) error {
+	checkDispatch()
 	err := C.form_driver(f.form, C.int(drvract))
 	return ncursesError(syscall.Errno(err))
 }
@@ -184,14 +462,196 @@ func (f *Form) Driver(drvract Key) error {
 // free'd by Go's garbage collection system so the memory allocated to
 // it must be explicitly free'd
 func (f *Form) Free() error {
+	checkDispatch()
+	id := uintptr(C.form_userptr(f.form))
 	err := C.free_form(f.form)
+	unposted(f)
+	if err == C.E_OK {
+		delete(formHooks, id)
+	}
 	f = nil
 	return ncursesError(syscall.Errno(err))
 }
 
+// The hooks which may be set on a form, as numbered by the C side
+const (
+	hookFieldEnter = iota
+	hookFieldLeave
+	hookPageEnter
+	hookPageLeave
+)
+
+// formHookFuncs are the functions called as the user moves around a form
+type formHookFuncs struct {
+	form  *Form
+	field [2]func(*Form, *Field) // entering and leaving a field
+	page  [2]func(*Form, int)    // entering and leaving a page
+}
+
+// formHooks are the hooks of each form, by the id stored as the form's user
+// pointer
+var (
+	formHooks  = make(map[uintptr]*formHookFuncs)
+	formHookID uintptr
+)
+
+// hooks returns the hooks of the form, and the id by which they are known,
+// creating them if need be. The hooks are passed f as their form.
+func (f *Form) hooks() (uintptr, *formHookFuncs) {
+	id := uintptr(C.form_userptr(f.form))
+	h, ok := formHooks[id]
+	if !ok {
+		formHookID++
+		id, h = formHookID, new(formHookFuncs)
+		formHooks[id] = h
+	}
+	h.form = f
+	return id, h
+}
+
+func (f *Form) setHook(id uintptr, hook int, on bool) error {
+	err := C.goncurses_set_form_hook(f.form, C.uintptr_t(id), C.int(hook),
+		cbool(on))
+	return ncursesError(syscall.Errno(err))
+}
+
+// OnFieldEnter sets fn to be called each time a field becomes the current
+// field, including when the form is posted. Passing nil removes the hook.
+func (f *Form) OnFieldEnter(fn func(form *Form, field *Field)) error {
+	checkDispatch()
+	id, h := f.hooks()
+	h.field[0] = fn
+	return f.setHook(id, hookFieldEnter, fn != nil)
+}
+
+// OnFieldLeave sets fn to be called each time the current field is about to
+// change, including when the form is unposted. Passing nil removes the hook.
+func (f *Form) OnFieldLeave(fn func(form *Form, field *Field)) error {
+	checkDispatch()
+	id, h := f.hooks()
+	h.field[1] = fn
+	return f.setHook(id, hookFieldLeave, fn != nil)
+}
+
+// OnPageEnter sets fn to be called each time a page of the form is shown,
+// including when the form is posted. Passing nil removes the hook.
+func (f *Form) OnPageEnter(fn func(form *Form, page int)) error {
+	checkDispatch()
+	id, h := f.hooks()
+	h.page[0] = fn
+	return f.setHook(id, hookPageEnter, fn != nil)
+}
+
+// OnPageLeave sets fn to be called each time the page shown is about to
+// change, including when the form is unposted. Passing nil removes the hook.
+func (f *Form) OnPageLeave(fn func(form *Form, page int)) error {
+	checkDispatch()
+	id, h := f.hooks()
+	h.page[1] = fn
+	return f.setHook(id, hookPageLeave, fn != nil)
+}
+
+// fields returns the fields attached to the form
+func (f *Form) fields() []*C.FIELD {
+	n := int(C.field_count(f.form))
+	if n <= 0 {
+		return nil
+	}
+	return (*[1 << 15]*C.FIELD)(unsafe.Pointer(C.form_fields(f.form)))[:n:n]
+}
+
+// fieldPages returns the page of each of the form's fields. Every field
+// but the first for which SetNewPage has been called starts a new page.
+func (f *Form) fieldPages() []int {
+	fields := f.fields()
+	pages := make([]int, len(fields))
+	for i := 1; i < len(fields); i++ {
+		pages[i] = pages[i-1]
+		if C.new_page(fields[i]) {
+			pages[i]++
+		}
+	}
+	return pages
+}
+
+// Page returns the index of the page shown, counting from zero (0)
+func (f *Form) Page() int {
+	checkDispatch()
+	return int(C.form_page(f.form))
+}
+
+// PageCount returns the number of pages in the form. Pages are started by
+// fields for which SetNewPage has been called.
+func (f *Form) PageCount() int {
+	checkDispatch()
+	pages := f.fieldPages()
+	if len(pages) == 0 {
+		return 0
+	}
+	return pages[len(pages)-1] + 1
+}
+
+// Paste enters text into the current field as though it had been typed,
+// except that the form does not skip to the next field once the current one
+// is full, as it would with FO_AUTOSKIP set; any text which does not fit is
+// discarded and an error returned. Line breaks start a new line in fields of
+// more than one line and are entered as blanks in others, while other
+// control characters are ignored. See BracketedPaste.
+func (f *Form) Paste(text string) error {
+	checkDispatch()
+	field := C.current_field(f.form)
+	if field == nil {
+		return errors.New("Form has no current field")
+	}
+	var rows C.int
+	C.field_info(field, &rows, nil, nil, nil, nil, nil)
+	for i, r := range text {
+		var err C.int
+		switch {
+		case r == '\n' && rows > 1:
+			if f.edit(REQ_NEW_LINE) != nil {
+				if i+1 < len(text) {
+					return errors.New("No room")
+				}
+				return nil
+			}
+		case r == '\n' || r == '\t':
+			err = C.form_driver_w(f.form, C.OK, C.wchar_t(' '))
+		case unicode.IsPrint(r):
+			err = C.form_driver_w(f.form, C.OK, C.wchar_t(r))
+		}
+		if err := ncursesError(syscall.Errno(err)); err != nil {
+			return err
+		}
+		// the field is full and the form has skipped to the next one
+		if C.current_field(f.form) != field {
+			C.set_current_field(f.form, field)
+			C.form_driver(f.form, C.REQ_END_FIELD)
+			if i+utf8.RuneLen(r) < len(text) {
+				return errors.New("No room")
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
 // Post the form, making it visible and interactive
 func (f *Form) Post() error {
+	checkDispatch()
 	err := C.post_form(f.form)
+	if err == C.E_OK {
+		posted(f)
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetCurrent makes field the form's current field, moving to its page if
+// need be. The form validates the field being left and fails if it is
+// invalid.
+func (f *Form) SetCurrent(field *Field) error {
+	checkDispatch()
+	err := C.set_current_field(f.form, (*C.FIELD)(field))
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -199,6 +659,7 @@ func (f *Form) Post() error {
 // It is important to make sure all prior fields have been freed otherwise
 // this action will result in a memory leak
 func (f *Form) SetFields(fields []*Field) error {
+	checkDispatch()
 	//cfields := make([]*C.FIELD, len(fields)+1)
 	//for index, field := range fields {
 	//cfields[index] = field.field
@@ -210,29 +671,43 @@ func (f *Form) SetFields(fields []*Field) error {
 
 // SetOptions for the form
 func (f *Form) SetOptions(opts int) error {
+	checkDispatch()
 	_, err := C.set_form_opts(f.form, (C.Form_Options)(opts))
 	return ncursesError(err)
 }
 
+// SetPage shows the page of the form with the given index, counting from
+// zero (0). The current field must be valid for the page to change.
+func (f *Form) SetPage(page int) error {
+	checkDispatch()
+	err := C.set_form_page(f.form, C.int(page))
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetSub sets the subwindow associated with the form
 func (f *Form) SetSub(w *Window) error {
+	checkDispatch()
 	err := int(C.set_form_sub(f.form, w.win))
 	return ncursesError(syscall.Errno(err))
 }
 
 // SetWindow sets the window associated with the form
 func (f *Form) SetWindow(w *Window) error {
+	checkDispatch()
 	err := int(C.set_form_win(f.form, w.win))
 	return ncursesError(syscall.Errno(err))
 }
 
 // Sub returns the subwindow associated with the form
 func (f *Form) Sub() Window {
+	checkDispatch()
 	return Window{C.form_sub(f.form)}
 }
 
 // UnPost the form, removing it from the interface
 func (f *Form) UnPost() error {
+	checkDispatch()
 	err := C.unpost_form(f.form)
+	unposted(f)
 	return ncursesError(syscall.Errno(err))
 }
@@ -0,0 +1,528 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestFormHooks(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	first, _ := goncurses.NewField(1, 5, 0, 0, 0, 0)
+	defer first.Free()
+	second, _ := goncurses.NewField(1, 5, 1, 0, 0, 0)
+	defer second.Free()
+	names := map[*goncurses.Field]string{first: "first", second: "second"}
+	form, _ := goncurses.NewForm([]*goncurses.Field{first, second})
+	defer form.Free()
+
+	var events []string
+	field := func(event string) func(*goncurses.Form, *goncurses.Field) {
+		return func(f *goncurses.Form, field *goncurses.Field) {
+			if f != &form {
+				t.Errorf("%s: hook passed the wrong form", event)
+			}
+			events = append(events, event+" "+names[field])
+		}
+	}
+	page := func(event string) func(*goncurses.Form, int) {
+		return func(f *goncurses.Form, page int) {
+			events = append(events, fmt.Sprintf("%s %d", event, page))
+		}
+	}
+	form.OnFieldEnter(field("enter"))
+	form.OnFieldLeave(field("leave"))
+	form.OnPageEnter(page("show"))
+	form.OnPageLeave(page("hide"))
+
+	form.Post()
+	form.Driver(goncurses.REQ_NEXT_FIELD)
+	form.OnFieldEnter(nil)
+	form.Driver(goncurses.REQ_PREV_FIELD)
+	form.UnPost()
+
+	expected := "show 0, enter first, leave first, enter second, " +
+		"leave second, leave first, hide 0"
+	if s := strings.Join(events, ", "); s != expected {
+		t.Errorf("got hooks %q; want %q", s, expected)
+	}
+}
+
+func TestWizard(t *testing.T) {
+	scr := newScreen(t, 6, 32)
+	defer scr.End()
+
+	var fields []*goncurses.Field
+	for i := 0; i < 4; i++ {
+		field, _ := goncurses.NewField(1, 5, int32(i%2), 0, 0, 0)
+		defer field.Free()
+		fields = append(fields, field)
+	}
+	fields[1].SetType(goncurses.IntegerType(0, 1, 10))
+	fields[1].Options(goncurses.FO_PASSOK|goncurses.FO_NULLOK, false)
+	fields[2].SetNewPage(true)
+	fields[3].SetNewPage(true)
+	form, _ := goncurses.NewForm(fields)
+	defer form.Free()
+	if n := form.PageCount(); n != 3 {
+		t.Fatalf("got %d pages; want 3", n)
+	}
+
+	win, _ := goncurses.NewWindow(6, 32, 0, 0)
+	wizard, err := goncurses.NewWizard(&form, win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wizard.Titles = []string{"Name", "Address"}
+	if err := wizard.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer wizard.UnPost()
+	win.Refresh()
+	if s := strings.TrimSpace(scr.Line(0)); s != "Step 1 of 3: Name" {
+		t.Errorf("got indicator %q", s)
+	}
+	if s := strings.TrimSpace(scr.Line(5)); s != "< Back > < Next > < Finish >" {
+		t.Errorf("got buttons %q", s)
+	}
+
+	// validating the page does not call the hooks for the fields visited
+	hooks := 0
+	form.OnFieldEnter(func(*goncurses.Form, *goncurses.Field) { hooks++ })
+	form.OnFieldLeave(func(*goncurses.Form, *goncurses.Field) { hooks++ })
+	keys := goncurses.NewWizardKeymap(nil)
+	next := goncurses.KeyEvent{Key: goncurses.KEY_PAGEDOWN}
+	if _, err := wizard.Drive(keys, next); err == nil {
+		t.Error("expected next to fail with an invalid field")
+	}
+	if hooks != 0 {
+		t.Errorf("got %d calls to the field hooks; want none", hooks)
+	}
+	form.OnFieldEnter(nil)
+	form.OnFieldLeave(nil)
+	if form.Page() != 0 {
+		t.Errorf("got page %d; want page 0", form.Page())
+	}
+	wizard.Drive(keys, goncurses.KeyEvent{Key: '5', Rune: '5'})
+	form.Driver(goncurses.REQ_VALIDATION)
+	if fields[1].Buffer() != "5    " {
+		t.Errorf("expected the invalid field to be current, got %q",
+			fields[1].Buffer())
+	}
+
+	wizard.Validate = func(page int) error {
+		if page == 1 {
+			return fmt.Errorf("page %d", page)
+		}
+		return nil
+	}
+	if action, err := wizard.Drive(keys, next); action != "next" ||
+		err != nil || form.Page() != 1 {
+		t.Errorf("got %q, %v and page %d; want next to page 1", action,
+			err, form.Page())
+	}
+	if err := wizard.Next(); err == nil || err.Error() != "page 1" {
+		t.Errorf("got %v; want error from Validate", err)
+	}
+	wizard.Validate = nil
+	if err := wizard.Finish(); err == nil {
+		t.Error("expected finish to fail before the last page")
+	}
+	wizard.Next()
+	win.Refresh()
+	if s := strings.TrimSpace(scr.Line(0)); s != "Step 3 of 3" {
+		t.Errorf("got indicator %q", s)
+	}
+
+	action, err := wizard.HandleMouse(&goncurses.MouseEvent{Y: 5, X: 3,
+		Button: 1, Action: goncurses.MOUSE_CLICK})
+	if action != "back" || err != nil || form.Page() != 1 {
+		t.Errorf("got %q, %v and page %d; want back to page 1", action,
+			err, form.Page())
+	}
+	wizard.Next()
+	if action, err := wizard.Drive(keys, goncurses.KeyEvent{Key: 'f',
+		Rune: 'f', Modifiers: goncurses.MOD_ALT}); action != "finish" ||
+		err != nil || !wizard.Finished() {
+		t.Errorf("got %q, %v; want wizard finished", action, err)
+	}
+}
+
+func TestFormFor(t *testing.T) {
+	scr := newScreen(t, 6, 40)
+	defer scr.End()
+
+	settings := struct {
+		Host    string `form:"label=Host name,required"`
+		Port    int    `form:"width=5,min=1,max=65535"`
+		Verbose bool
+		Mode    string `form:"type=enum,values=fast|safe"`
+		secret  string
+		Skipped string `form:"-"`
+	}{Host: "localhost", Port: 80, Mode: "safe"}
+
+	if _, err := goncurses.FormFor(settings); err == nil {
+		t.Error("expected an error for a struct which is not a pointer")
+	}
+	sf, err := goncurses.FormFor(&settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Free()
+	form := sf.Form()
+	form.Post()
+	goncurses.StdScr().Refresh()
+
+	expected := []string{"Host name: localhost", "Port:      80",
+		"Verbose:   no", "Mode:      safe"}
+	for i, line := range expected {
+		if s := strings.TrimSpace(scr.Line(i)); s != line {
+			t.Errorf("line %d: got %q; want %q", i, s, line)
+		}
+	}
+
+	form.Driver(goncurses.REQ_CLR_FIELD)
+	form.Driver(goncurses.REQ_NEXT_FIELD)
+	form.Driver(goncurses.REQ_CLR_FIELD)
+	for _, ch := range "70000" {
+		form.Driver(goncurses.Key(ch))
+	}
+	err = sf.Submit()
+	errs, ok := err.(goncurses.FormErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("got %v; want errors for Host name and Port", err)
+	}
+	if errs[0].Name != "Host" || errs[0].Error() != "Host name: required" {
+		t.Errorf("got %q for the first error", errs[0].Error())
+	}
+	if errs[1].Name != "Port" {
+		t.Errorf("got %q for the second error", errs[1].Error())
+	}
+	if settings.Port != 80 || settings.Host != "localhost" {
+		t.Error("struct changed by a failed submit")
+	}
+
+	form.Driver(goncurses.REQ_CLR_FIELD)
+	for _, ch := range "8080" {
+		form.Driver(goncurses.Key(ch))
+	}
+	form.Driver(goncurses.REQ_PREV_FIELD)
+	for _, ch := range "example.com" {
+		form.Driver(goncurses.Key(ch))
+	}
+	form.Driver(goncurses.REQ_LAST_FIELD)
+	form.Driver(goncurses.REQ_NEXT_CHOICE)
+	form.Driver(goncurses.REQ_PREV_FIELD)
+	form.Driver(goncurses.REQ_NEXT_CHOICE)
+	if err := sf.Submit(); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Host != "example.com" || settings.Port != 8080 ||
+		!settings.Verbose || settings.Mode != "fast" {
+		t.Errorf("got %+v", settings)
+	}
+}
+
+func TestFormForRegexp(t *testing.T) {
+	scr := newScreen(t, 5, 40)
+	defer scr.End()
+
+	// \d is understood by package regexp but not by POSIX expressions
+	code := struct {
+		Code string `form:"regexp=[A-Z]{2}\\d+"`
+	}{Code: "AB12"}
+	sf, err := goncurses.FormFor(&code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Free()
+	form := sf.Form()
+	form.Post()
+	defer form.UnPost()
+	if err := form.Driver(goncurses.REQ_VALIDATION); err != nil {
+		t.Errorf("got %v validating %q", err, "AB12")
+	}
+	form.Driver(goncurses.REQ_END_FIELD)
+	form.Driver('x')
+	if err := form.Driver(goncurses.REQ_VALIDATION); err == nil {
+		t.Error("expected AB12x to be refused, as the whole must match")
+	}
+	if err := sf.Submit(); err == nil {
+		t.Error("expected Submit to fail with an invalid field")
+	}
+	form.Driver(goncurses.REQ_DEL_PREV)
+	form.Driver('3')
+	if err := sf.Submit(); err != nil || code.Code != "AB123" {
+		t.Errorf("got %q, %v; want AB123", code.Code, err)
+	}
+}
+
+func TestLinkedFieldBuffer(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	first, _ := goncurses.NewField(1, 5, 0, 0, 0, 0)
+	defer first.Free()
+	second, err := first.Link(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Free()
+	third, _ := second.Link(2, 0)
+	defer third.Free()
+
+	first.SetBuffer("abc")
+	for _, f := range []*goncurses.Field{second, third} {
+		if s := f.Buffer(); s != "abc  " {
+			t.Errorf("got buffer %q; want %q", s, "abc  ")
+		}
+		if s := f.Text(); s != "abc" {
+			t.Errorf("got text %q; want %q", s, "abc")
+		}
+	}
+	third.SetBuffer("xy")
+	if s := second.Text(); s != "xy" {
+		t.Errorf("got text %q after setting a linked buffer; want %q", s,
+			"xy")
+	}
+	if err := first.Free(); err == nil {
+		t.Error("expected freeing a field before those linked to it to fail")
+	}
+}
+
+func TestFieldAccessors(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	first, _ := goncurses.NewField(1, 5, 0, 0, 1, 0)
+	defer first.Free()
+	first.SetUserData("model")
+	second, err := first.Link(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Free()
+	form, _ := goncurses.NewForm([]*goncurses.Field{first, second})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+
+	if h, w, y, x, off, nbuf, err := second.Info(); err != nil ||
+		h != 1 || w != 5 || y != 1 || x != 0 || off != 1 || nbuf != 0 {
+		t.Errorf("got %d %d %d %d %d %d %v", h, w, y, x, off, nbuf, err)
+	}
+	if second.Index() != 1 || second.UserData() != "model" {
+		t.Errorf("got index %d and data %v", second.Index(),
+			second.UserData())
+	}
+	if rows, cols, max, err := first.DynamicInfo(); err != nil ||
+		rows != 2 || cols != 5 || max != 0 {
+		t.Errorf("got %d rows, %d cols, max %d, %v", rows, cols, max, err)
+	}
+
+	if form.Current() != first || first.Status() {
+		t.Error("expected the first field, unchanged, to be current")
+	}
+	form.Driver('a')
+	if err := form.SetCurrent(second); err != nil || form.Current() != second {
+		t.Fatalf("failed to make the second field current: %v", err)
+	}
+	if !first.Status() || !second.Status() || first.Buffer()[:1] != "a" {
+		t.Error("expected the linked fields to be changed")
+	}
+	first.SetStatus(false)
+	if first.Status() {
+		t.Error("expected the status to be cleared")
+	}
+
+	if first.Opts()&goncurses.FO_ACTIVE == 0 {
+		t.Error("expected the field to be active")
+	}
+	first.Options(goncurses.FO_ACTIVE, false)
+	if first.Opts()&goncurses.FO_ACTIVE != 0 {
+		t.Error("expected the field to be inactive")
+	}
+	first.SetOpts(goncurses.FO_VISIBLE | goncurses.FO_ACTIVE)
+	if first.Opts() != goncurses.FO_VISIBLE|goncurses.FO_ACTIVE {
+		t.Errorf("got options %x", first.Opts())
+	}
+	first.SetUserData(nil)
+	if first.UserData() != nil {
+		t.Error("expected the user data to be removed")
+	}
+}
+
+func TestMultiLineField(t *testing.T) {
+	scr := newScreen(t, 10, 20)
+	defer scr.End()
+
+	notes, _ := goncurses.NewField(3, 6, 0, 0, 1, 0)
+	defer notes.Free()
+	other, _ := goncurses.NewField(1, 6, 5, 0, 0, 0)
+	defer other.Free()
+	form1, _ := goncurses.NewForm([]*goncurses.Field{notes, other})
+	defer form1.Free()
+	form := &form1
+	form.Post()
+
+	keys := goncurses.NewFormKeymap(nil)
+	enter := goncurses.KeyEvent{Key: goncurses.KEY_RETURN}
+	backspace := goncurses.KeyEvent{Key: goncurses.KEY_BACKSPACE}
+	typeText := func(s string) {
+		for _, r := range s {
+			if r == '\n' {
+				form.Drive(keys, enter)
+				continue
+			}
+			form.Drive(keys, goncurses.KeyEvent{Key: goncurses.Key(r), Rune: r})
+		}
+	}
+	typeText("one\ntwo\nthree\nfour")
+	if _, err := form.Drive(keys, enter); err == nil {
+		t.Error("expected a new line on the last line to be refused")
+	}
+	if form.Current() != notes {
+		t.Fatal("expected the notes field to stay current")
+	}
+	form.Driver(goncurses.REQ_VALIDATION)
+	if s := notes.Text(); s != "one\ntwo\nthree\nfour" {
+		t.Errorf("got %q", s)
+	}
+
+	form.Driver(goncurses.REQ_BEG_LINE)
+	form.Drive(keys, backspace)
+	form.Driver(goncurses.REQ_VALIDATION)
+	if s := notes.Text(); s != "one\ntwo\nthree\nfour" {
+		t.Errorf("got %q after a backspace which could not join", s)
+	}
+	form.Driver(goncurses.REQ_BEG_FIELD)
+	if _, err := form.Drive(keys, backspace); err == nil ||
+		form.Current() != notes {
+		t.Error("expected a backspace at the start to be refused")
+	}
+	form.Driver(goncurses.REQ_DOWN_CHAR)
+	form.Drive(keys, backspace)
+	form.Driver(goncurses.REQ_VALIDATION)
+	if s := notes.Text(); s != "onetwo\nthree\nfour" {
+		t.Errorf("got %q after joining lines", s)
+	}
+
+	grow, _ := goncurses.NewField(2, 6, 0, 0, 0, 0)
+	defer grow.Free()
+	if err := grow.SetDynamic(true); err != nil || !grow.Dynamic() {
+		t.Fatal("failed to make the field dynamic")
+	}
+	grow.SetMax(4)
+	form.UnPost()
+	form2, _ := goncurses.NewForm([]*goncurses.Field{grow})
+	defer form2.Free()
+	form = &form2
+	form.Post()
+	defer form.UnPost()
+	typeText("a\nb\nc\nd")
+	if _, err := form.Drive(keys, enter); err == nil {
+		t.Error("expected a new line to be refused at the maximum size")
+	}
+	form.Driver(goncurses.REQ_VALIDATION)
+	if rows, _, _, _ := grow.DynamicInfo(); rows != 4 {
+		t.Errorf("got %d rows; want 4", rows)
+	}
+	if s := grow.Text(); s != "a\nb\nc\nd" {
+		t.Errorf("got %q from the dynamic field", s)
+	}
+}
+
+func TestFieldMask(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	phone, _ := goncurses.NewField(1, 14, 0, 0, 0, 0)
+	defer phone.Free()
+	date, _ := goncurses.NewField(1, 12, 1, 0, 0, 0)
+	defer date.Free()
+	if err := phone.SetMask("(###) ###-####"); err != nil {
+		t.Fatal(err)
+	}
+	if err := date.SetMask("YYYY-MM-DD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := date.SetMask("YYYY-MM-DD ##:##"); err == nil {
+		t.Error("expected an error for a mask wider than the field")
+	}
+	form, _ := goncurses.NewForm([]*goncurses.Field{phone, date})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+
+	keys := goncurses.NewFormKeymap(nil)
+	typeText := func(s string) (err error) {
+		for _, r := range s {
+			_, err = form.Drive(keys, goncurses.KeyEvent{Key: goncurses.Key(r),
+				Rune: r})
+		}
+		return err
+	}
+	if err := typeText("x"); err == nil {
+		t.Error("expected a letter to be refused")
+	}
+	typeText("555123")
+	if err := form.Driver(goncurses.REQ_NEXT_FIELD); err == nil {
+		t.Error("expected an incomplete field to be invalid")
+	}
+	form.Drive(keys, goncurses.KeyEvent{Key: goncurses.KEY_BACKSPACE})
+	typeText("4567")
+	if s := phone.Buffer(); s != "(555) 124-567 " {
+		t.Errorf("got display %q", s)
+	}
+	typeText("8")
+	if form.Current() != date {
+		t.Fatal("expected the form to skip to the next field once full")
+	}
+	if s := phone.Raw(); s != "5551245678" {
+		t.Errorf("got raw value %q", s)
+	}
+
+	typeText("20261018")
+	if s, raw := date.Text(), date.Raw(); s != "2026-10-18" ||
+		raw != "20261018" {
+		t.Errorf("got %q and %q", s, raw)
+	}
+	if err := date.SetRaw("2026x"); err == nil {
+		t.Error("expected a letter to be refused by SetRaw")
+	}
+	// the form skipped back to the first field once the date was full
+	form.SetCurrent(date)
+	for _, test := range []struct {
+		raw   string
+		valid bool
+	}{{"20240229", true}, {"20230229", false}, {"20261301", false},
+		{"20260431", false}, {"20260100", false}, {"20261231", true}} {
+		date.SetRaw(test.raw)
+		if err := form.Driver(goncurses.REQ_VALIDATION); (err == nil) !=
+			test.valid {
+			t.Errorf("%s: got %v validating; want valid %v", test.raw, err,
+				test.valid)
+		}
+	}
+	form.Driver(goncurses.REQ_PREV_FIELD)
+	if err := phone.SetRaw("5550100"); err != nil {
+		t.Fatal(err)
+	}
+	if s := phone.Buffer(); s != "(555) 010-0   " {
+		t.Errorf("got display %q after SetRaw", s)
+	}
+	if phone.Mask() != "(###) ###-####" {
+		t.Errorf("got mask %q", phone.Mask())
+	}
+}
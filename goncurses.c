@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+#include <limits.h>
+#include <locale.h>
 #include <stdbool.h>
 #include <stdlib.h>
 #include <curses.h>
@@ -89,6 +91,123 @@ int ncurses_wattrset(WINDOW *win, int attr) { return wattrset(win, attr); }
 int ncurses_wstandend(WINDOW *win) { return wstandend(win); }
 int ncurses_wstandout(WINDOW *win) { return wstandout(win); }
 
+/* Color pairs and colors beyond the range of a short, and color pairs which
+ * are not encoded in the attribute bits, require the ncurses 6.1 extended
+ * color functions. Otherwise fall back to the standard functions. */
+#if NCURSES_EXT_COLORS && NCURSES_VERSION_PATCH >= 20170401
+#define GONCURSES_EXT_COLORS 1
+#endif
+
+int ncurses_alloc_pair(int fg, int bg) {
+#ifdef GONCURSES_EXT_COLORS
+	return alloc_pair(fg, bg);
+#else
+	return -1;
+#endif
+}
+
+int ncurses_find_pair(int fg, int bg) {
+#ifdef GONCURSES_EXT_COLORS
+	return find_pair(fg, bg);
+#else
+	return -1;
+#endif
+}
+
+int ncurses_free_pair(int pair) {
+#ifdef GONCURSES_EXT_COLORS
+	return free_pair(pair);
+#else
+	return ERR;
+#endif
+}
+
+bool ncurses_has_alloc_pair(void) {
+#ifdef GONCURSES_EXT_COLORS
+	return true;
+#else
+	return false;
+#endif
+}
+
+int ncurses_extended_color_content(int color, int *r, int *g, int *b) {
+#ifdef GONCURSES_EXT_COLORS
+	return extended_color_content(color, r, g, b);
+#else
+	short sr, sg, sb;
+	int res = color_content((short) color, &sr, &sg, &sb);
+	*r = sr, *g = sg, *b = sb;
+	return res;
+#endif
+}
+
+int ncurses_extended_pair_content(int pair, int *fg, int *bg) {
+#ifdef GONCURSES_EXT_COLORS
+	return extended_pair_content(pair, fg, bg);
+#else
+	short sf, sb;
+	int res = pair_content((short) pair, &sf, &sb);
+	*fg = sf, *bg = sb;
+	return res;
+#endif
+}
+
+int ncurses_init_extended_color(int color, int r, int g, int b) {
+#ifdef GONCURSES_EXT_COLORS
+	return init_extended_color(color, r, g, b);
+#else
+	if (color > SHRT_MAX)
+		return ERR;
+	return init_color((short) color, (short) r, (short) g, (short) b);
+#endif
+}
+
+int ncurses_init_extended_pair(int pair, int fg, int bg) {
+#ifdef GONCURSES_EXT_COLORS
+	return init_extended_pair(pair, fg, bg);
+#else
+	if (pair > SHRT_MAX || fg > SHRT_MAX || bg > SHRT_MAX)
+		return ERR;
+	return init_pair((short) pair, (short) fg, (short) bg);
+#endif
+}
+
+int ncurses_setcchar(cchar_t *wcval, wchar_t wch, attr_t attrs, int pair) {
+	wchar_t wstr[2] = { wch, L'\0' };
+#ifdef GONCURSES_EXT_COLORS
+	return setcchar(wcval, wstr, attrs, 0, &pair);
+#else
+	return setcchar(wcval, wstr, attrs, (short) pair, NULL);
+#endif
+}
+
+#ifndef CCHARW_MAX
+#define CCHARW_MAX 5
+#endif
+
+int ncurses_getcchar(const cchar_t *wcval, wchar_t *wch, attr_t *attrs,
+		int *pair) {
+	wchar_t wstr[CCHARW_MAX + 1];
+	short spair = 0;
+	int res;
+#ifdef GONCURSES_EXT_COLORS
+	res = getcchar(wcval, wstr, attrs, &spair, pair);
+#else
+	res = getcchar(wcval, wstr, attrs, &spair, NULL);
+	*pair = spair;
+#endif
+	*wch = wstr[0];
+	return res;
+}
+
+int ncurses_wcolor_set(WINDOW *win, int pair) {
+#ifdef GONCURSES_EXT_COLORS
+	return wcolor_set(win, 0, &pair);
+#else
+	return wcolor_set(win, (short) pair, NULL);
+#endif
+}
+
 bool goncurses_set_escdelay(int size) {
 #ifdef PDCURSES
   return false;
@@ -97,3 +216,49 @@ bool goncurses_set_escdelay(int size) {
   return true;
 #endif
 }
+
+void goncurses_setlocale(void) {
+	setlocale(LC_ALL, "");
+}
+
+int ncurses_define_key(const char *definition, int keycode) {
+#ifdef PDCURSES
+	return ERR;
+#else
+	return define_key(definition, keycode);
+#endif
+}
+
+int ncurses_key_defined(const char *definition) {
+#ifdef PDCURSES
+	return 0;
+#else
+	return key_defined(definition);
+#endif
+}
+
+char *ncurses_keybound(int keycode, int count) {
+#ifdef PDCURSES
+	return NULL;
+#else
+	return keybound(keycode, count);
+#endif
+}
+
+int ncurses_keyok(int keycode, bool enable) {
+#ifdef PDCURSES
+	return ERR;
+#else
+	return keyok(keycode, enable);
+#endif
+}
+
+int ncurses_wgetdelay(const WINDOW *win) {
+#ifdef PDCURSES
+	if (win->_nodelay)
+		return 0;
+	return win->_delayms > 0 ? win->_delayms : -1;
+#else
+	return wgetdelay(win);
+#endif
+}
@@ -30,6 +30,24 @@ int ncurses_wattrset(WINDOW *win, int attr);
 WINDOW * ncurses_wgetparent(const WINDOW *win);
 int ncurses_wstandend(WINDOW *win);
 int ncurses_wstandout(WINDOW *win);
+int ncurses_alloc_pair(int fg, int bg);
+int ncurses_define_key(const char *definition, int keycode);
+int ncurses_extended_color_content(int color, int *r, int *g, int *b);
+int ncurses_extended_pair_content(int pair, int *fg, int *bg);
+int ncurses_find_pair(int fg, int bg);
+int ncurses_free_pair(int pair);
+bool ncurses_has_alloc_pair(void);
+int ncurses_init_extended_color(int color, int r, int g, int b);
+int ncurses_init_extended_pair(int pair, int fg, int bg);
+int ncurses_key_defined(const char *definition);
+char *ncurses_keybound(int keycode, int count);
+int ncurses_keyok(int keycode, bool enable);
+int ncurses_wgetdelay(const WINDOW *win);
+int ncurses_setcchar(cchar_t *wcval, wchar_t wch, attr_t attrs, int pair);
+int ncurses_getcchar(const cchar_t *wcval, wchar_t *wch, attr_t *attrs,
+		int *pair);
+int ncurses_wcolor_set(WINDOW *win, int pair);
 bool goncurses_set_escdelay(int size);
+void goncurses_setlocale(void);
 
 #endif /* _GONCURSES_ */
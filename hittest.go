@@ -0,0 +1,180 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// #include <curses.h>
+// #include <form.h>
+// #include <menu.h>
+// #include <panel.h>
+import "C"
+
+import "unsafe"
+
+// Hit describes what is on the screen at a position, as returned by HitTest
+type Hit struct {
+	Panel  *Panel    // topmost panel at the position, or nil
+	Window *Window   // window at the position
+	Y, X   int       // position relative to Window
+	Menu   *Menu     // posted menu at the position, or nil
+	Item   *MenuItem // menu item at the position, or nil
+	Form   *Form     // posted form at the position, or nil
+	Field  *Field    // form field at the position, or nil
+}
+
+// hitTarget is a posted menu or form
+type hitTarget interface {
+	windows() (win, sub *C.WINDOW)
+	hit(y, x int, h *Hit)
+}
+
+// postedTargets are the menus and forms currently posted, most recent last
+var postedTargets []hitTarget
+
+func posted(t hitTarget) {
+	unposted(t)
+	postedTargets = append(postedTargets, t)
+}
+
+func unposted(t hitTarget) {
+	for i, p := range postedTargets {
+		if p == t {
+			postedTargets = append(postedTargets[:i], postedTargets[i+1:]...)
+			return
+		}
+	}
+}
+
+// HitTest returns what is at the screen position y, x, such as that of a
+// MouseEvent. Panels are searched from the top of the stack down and the
+// first whose window encloses the position is returned. Failing that, the
+// window of a posted menu or form, or else the standard screen, is used. If
+// a menu or form is posted in the window, the item or field at the position
+// is also returned. Nil is returned if the position is off the screen.
+func HitTest(y, x int) *Hit {
+	checkDispatch()
+	h := new(Hit)
+	for p := (*Panel)(nil).Below(); p != nil; p = p.Below() {
+		if w := p.Window(); w.Enclose(y, x) {
+			h.Panel, h.Window = p, w
+			break
+		}
+	}
+	for i := len(postedTargets) - 1; h.Window == nil && i >= 0; i-- {
+		if win, _ := postedTargets[i].windows(); win != nil &&
+			C.wenclose(win, C.int(y), C.int(x)) {
+			h.Window = &Window{win}
+		}
+	}
+	if h.Window == nil {
+		if h.Window = StdScr(); !h.Window.Enclose(y, x) {
+			return nil
+		}
+	}
+	by, bx := h.Window.YX()
+	h.Y, h.X = y-by, x-bx
+
+	for i := len(postedTargets) - 1; i >= 0; i-- {
+		t := postedTargets[i]
+		if win, sub := t.windows(); win == h.Window.win || sub == h.Window.win {
+			t.hit(y, x, h)
+			break
+		}
+	}
+	return h
+}
+
+// subPosition returns the position y, x relative to the window sub, and
+// false if sub does not enclose it
+func subPosition(sub *C.WINDOW, y, x int) (int, int, bool) {
+	cy, cx := C.int(y), C.int(x)
+	if !C.wmouse_trafo(sub, &cy, &cx, false) {
+		return 0, 0, false
+	}
+	return int(cy), int(cx), true
+}
+
+// windows returns the menu's own windows, rather than the pad ncurses draws
+// it on
+func (m *Menu) windows() (win, sub *C.WINDOW) {
+	return C.menu_win(m.menu), C.menu_sub(m.menu)
+}
+
+func (m *Menu) hit(y, x int, h *Hit) {
+	h.Menu = m
+	if item := m.itemAt(y, x); item != nil {
+		h.Item = &MenuItem{item}
+	}
+}
+
+// itemAt returns the item displayed at the screen position y, x or nil
+func (m *Menu) itemAt(y, x int) *C.ITEM {
+	_, sub := m.windows()
+	y, x, ok := subPosition(sub, y, x)
+	n := int(C.item_count(m.menu))
+	if !ok || n < 1 {
+		return nil
+	}
+	// items are laid out in as many rows as needed of at most the menu
+	// format's number of columns, each as wide as the widest item
+	var frows, fcols, spcDesc, spcRows, spcCols, height, width C.int
+	C.menu_format(m.menu, &frows, &fcols)
+	C.menu_spacing(m.menu, &spcDesc, &spcRows, &spcCols)
+	if C.scale_menu(m.menu, &height, &width) != C.E_OK || fcols < 1 {
+		return nil
+	}
+	rows := (n-1)/int(fcols) + 1
+	cols := (n-1)/rows + 1
+	rowMajor := C.menu_opts(m.menu)&C.O_ROWMAJOR != 0
+	if rowMajor && n < int(fcols) {
+		cols = n
+	} else if rowMajor {
+		cols = int(fcols)
+	}
+	gap := int(spcCols)
+	itemWidth := (int(width) - (cols-1)*gap) / cols
+	if spcRows < 1 || y%int(spcRows) != 0 || x%(itemWidth+gap) >= itemWidth {
+		return nil
+	}
+	row := int(C.top_row(m.menu)) + y/int(spcRows)
+	col := x / (itemWidth + gap)
+	i := col*rows + row
+	if rowMajor {
+		i = row*cols + col
+	}
+	if row >= rows || col >= cols || i >= n {
+		return nil
+	}
+	return (*[1 << 15]*C.ITEM)(unsafe.Pointer(C.menu_items(m.menu)))[i]
+}
+
+func (f *Form) windows() (win, sub *C.WINDOW) {
+	return C.form_win(f.form), C.form_sub(f.form)
+}
+
+func (f *Form) hit(y, x int, h *Hit) {
+	h.Form = f
+	_, sub := f.windows()
+	y, x, ok := subPosition(sub, y, x)
+	if !ok {
+		return
+	}
+	page := int(C.form_page(f.form))
+	pages := f.fieldPages()
+	for i, field := range f.fields() {
+		if pages[i] != page || C.field_opts(field)&C.O_VISIBLE == 0 {
+			continue
+		}
+		var rows, cols, top, left, offscreen, nbuf C.int
+		C.field_info(field, &rows, &cols, &top, &left, &offscreen, &nbuf)
+		if y >= int(top) && y < int(top+rows) &&
+			x >= int(left) && x < int(left+cols) {
+			h.Field = (*Field)(field)
+			return
+		}
+	}
+}
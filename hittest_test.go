@@ -0,0 +1,191 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestHitTest(t *testing.T) {
+	scr := newScreen(t, 24, 80)
+	defer scr.End()
+
+	field, _ := goncurses.NewField(1, 10, 2, 5, 0, 0)
+	defer field.Free()
+	form, _ := goncurses.NewForm([]*goncurses.Field{field})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+
+	back, _ := goncurses.NewWindow(10, 40, 4, 10)
+	lower := goncurses.NewPanel(back)
+	defer lower.Delete()
+	win, _ := goncurses.NewWindow(6, 30, 5, 20)
+	upper := goncurses.NewPanel(win)
+	defer upper.Delete()
+
+	var items []*goncurses.MenuItem
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		item, _ := goncurses.NewItem(name, "")
+		defer item.Free()
+		items = append(items, item)
+	}
+	menu, _ := goncurses.NewMenu(items)
+	defer menu.Free()
+	menu.SetWindow(win)
+	menu.SubWindow(win.Derived(4, 28, 1, 1))
+	menu.Format(3, 2)
+	menu.Mark("")
+	menu.Post()
+	defer menu.UnPost()
+	goncurses.UpdatePanels()
+
+	if p := upper.Below(); p == nil || p.Window().Enclose(4, 10) != true {
+		t.Errorf("expected lower panel below upper")
+	}
+	if upper.Above() != nil {
+		t.Errorf("expected no panel above the top panel")
+	}
+
+	// items are laid out row by row, each one column wide plus a space
+	h := goncurses.HitTest(7, 23)
+	if h == nil || h.Window == nil || h.Item == nil {
+		t.Fatalf("got %+v; want item", h)
+	}
+	if h.Y != 2 || h.X != 3 || h.Item.Name() != "d" {
+		t.Errorf("got item %q at %d,%d; want \"d\" at 2,3", h.Item.Name(),
+			h.Y, h.X)
+	}
+	menu.Driver(goncurses.REQ_DLINE)
+	if h := goncurses.HitTest(6, 21); h.Item == nil || h.Item.Name() != "c" {
+		t.Errorf("got %+v after scrolling; want item \"c\"", h)
+	}
+	if h := goncurses.HitTest(6, 22); h.Menu != menu || h.Item != nil {
+		t.Errorf("got %+v; want no item between columns", h)
+	}
+
+	if h := goncurses.HitTest(5, 12); h.Window == nil || h.Menu != nil ||
+		h.Y != 1 || h.X != 2 {
+		t.Errorf("got %+v; want lower panel", h)
+	}
+	h = goncurses.HitTest(2, 8)
+	if h.Panel != nil || h.Form != &form || h.Field == nil {
+		t.Errorf("got %+v; want form field on stdscr", h)
+	}
+	if h := goncurses.HitTest(30, 0); h != nil {
+		t.Errorf("got %+v; want nil off the screen", h)
+	}
+}
+
+func TestMenuHandleMouse(t *testing.T) {
+	scr := newScreen(t, 24, 80)
+	defer scr.End()
+
+	var items []*goncurses.MenuItem
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		item, _ := goncurses.NewItem(name, "")
+		defer item.Free()
+		items = append(items, item)
+	}
+	menu, _ := goncurses.NewMenu(items)
+	defer menu.Free()
+	win, _ := goncurses.NewWindow(2, 10, 5, 20)
+	menu.SetWindow(win)
+	menu.SubWindow(win)
+	menu.Format(2, 2)
+	menu.Mark("")
+	menu.Option(goncurses.O_ONEVALUE, false)
+	menu.Post()
+	defer menu.UnPost()
+
+	click := func(action goncurses.MouseAction, button, y, x int) error {
+		return menu.HandleMouse(&goncurses.MouseEvent{Y: y, X: x,
+			Button: button, Action: action})
+	}
+	if err := click(goncurses.MOUSE_CLICK, 1, 6, 22); err != nil {
+		t.Fatal(err)
+	}
+	if name := menu.Current(nil).Name(); name != "d" {
+		t.Errorf("got current item %q after click; want \"d\"", name)
+	}
+	if err := click(goncurses.MOUSE_WHEEL, 5, 5, 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := click(goncurses.MOUSE_DOUBLE_CLICK, 1, 6, 20); err != nil {
+		t.Fatal(err)
+	}
+	if item := menu.Current(nil); item.Name() != "e" || !item.Value() {
+		t.Errorf("got item %q selected %v after scrolling and double "+
+			"clicking; want \"e\" selected", item.Name(), item.Value())
+	}
+	if err := click(goncurses.MOUSE_CLICK, 1, 10, 20); err == nil {
+		t.Error("expected error clicking outside the menu")
+	}
+}
+
+func TestHitTestLayout(t *testing.T) {
+	scr := newScreen(t, 24, 80)
+	defer scr.End()
+
+	var items []*goncurses.MenuItem
+	for i, name := range []string{"one", "two", "three", "four", "five"} {
+		item, _ := goncurses.NewItem(name, string(rune('1'+i)))
+		defer item.Free()
+		items = append(items, item)
+	}
+	menu, _ := goncurses.NewMenu(items)
+	defer menu.Free()
+	win, _ := goncurses.NewWindow(2, 30, 10, 0)
+	menu.SetWindow(win)
+	menu.SubWindow(win)
+	menu.Mark("")
+	menu.Option(goncurses.O_ROWMAJOR, false)
+	menu.Format(2, 3)
+	menu.Post()
+	defer menu.UnPost()
+
+	// items are laid out column by column, each as wide as the longest name
+	// and description with a space between them, then a space
+	tests := []struct {
+		y, x int
+		name string
+	}{{11, 8, "four"}, {10, 16, "five"}, {10, 0, "one"}, {10, 7, ""},
+		{11, 16, ""}}
+	for _, test := range tests {
+		h := goncurses.HitTest(test.y, test.x)
+		switch {
+		case h == nil || h.Menu != menu:
+			t.Errorf("%d,%d: got %+v; want menu", test.y, test.x, h)
+		case test.name == "" && h.Item != nil:
+			t.Errorf("%d,%d: got item %q; want none", test.y, test.x,
+				h.Item.Name())
+		case test.name != "" && (h.Item == nil || h.Item.Name() != test.name):
+			t.Errorf("%d,%d: got %+v; want item %q", test.y, test.x, h.Item,
+				test.name)
+		}
+	}
+
+	first, _ := goncurses.NewField(1, 10, 2, 5, 0, 0)
+	defer first.Free()
+	second, _ := goncurses.NewField(1, 10, 2, 5, 0, 0)
+	defer second.Free()
+	second.SetNewPage(true)
+	form, _ := goncurses.NewForm([]*goncurses.Field{first, second})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+	if h := goncurses.HitTest(2, 6); h.Field != first {
+		t.Errorf("got %+v; want the first page's field", h)
+	}
+	form.SetPage(1)
+	if h := goncurses.HitTest(2, 6); h.Field != second {
+		t.Errorf("got %+v; want the second page's field", h)
+	}
+}
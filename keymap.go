@@ -0,0 +1,306 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// KeymapTimeout is the default time a Keymap waits for the next key of a
+// sequence before giving up on it
+var KeymapTimeout = time.Second
+
+// keyStroke is a single key of a sequence, as decoded by DecodeKey
+type keyStroke struct {
+	key Key
+	mod Modifier
+}
+
+type keyNode struct {
+	action string
+	next   map[keyStroke]*keyNode
+}
+
+// Keymap binds sequences of keys to named actions. A sequence may be a
+// single key, such as "q" or "C-<left>", or a chord of several keys
+// separated by spaces, such as "C-x C-s". Keys are written as accepted by
+// ParseKey, with the addition that "M-" (alt) may be used with any key.
+//
+// Keymaps may be layered by setting Parent. Keys are looked up in the keymap
+// first and then in each of its parents in turn, so that for example a
+// widget's keymap can override the bindings of its window's keymap which in
+// turn overrides those of a global one. The first keymap to bind a sequence,
+// or a prefix of one, decides what it does.
+//
+// Keys are added to the sequence being typed with Feed, or read from a window
+// with Read. A Keymap, other than its parents, should only be fed from one
+// source of input.
+type Keymap struct {
+	// Parent, if not nil, is consulted for sequences not bound here
+	Parent *Keymap
+
+	// Timeout is the longest time to wait between the keys of a sequence.
+	// Zero (0) waits indefinitely.
+	Timeout time.Duration
+
+	root    keyNode
+	pending []keyStroke
+	last    time.Time
+}
+
+// NewKeymap returns an empty keymap layered over parent, which may be nil.
+// The new keymap's Timeout is set to KeymapTimeout.
+func NewKeymap(parent *Keymap) *Keymap {
+	return &Keymap{Parent: parent, Timeout: KeymapTimeout}
+}
+
+// Bind binds the key sequence seq to action, replacing any previous binding
+// of the sequence. Sequences which start with seq remain bound; seq is then
+// only performed if no further key is typed before the timeout expires.
+func (km *Keymap) Bind(seq string, action string) error {
+	if action == "" {
+		return errors.New("Keymap action must not be empty")
+	}
+	strokes, err := parseKeySequence(seq)
+	if err != nil {
+		return err
+	}
+	n := &km.root
+	for _, s := range strokes {
+		if n.next == nil {
+			n.next = make(map[keyStroke]*keyNode)
+		}
+		if n.next[s] == nil {
+			n.next[s] = new(keyNode)
+		}
+		n = n.next[s]
+	}
+	n.action = action
+	return nil
+}
+
+// Unbind removes the binding of the key sequence seq, if any. Bindings in
+// parent keymaps are not affected.
+func (km *Keymap) Unbind(seq string) error {
+	strokes, err := parseKeySequence(seq)
+	if err != nil {
+		return err
+	}
+	if n := km.root.find(strokes); n != nil {
+		n.action = ""
+	}
+	return nil
+}
+
+// Load adds the bindings read from r. The bindings may either be a JSON
+// object mapping key sequences to actions:
+//
+//	{"C-x C-s": "save", "C-q": "quit"}
+//
+// or text with one binding per line, in which the sequence and action are
+// separated by an equal sign and lines starting with # are ignored:
+//
+//	# save and quit
+//	C-x C-s = save
+//	C-q     = quit
+func (km *Keymap) Load(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var bindings map[string]string
+		if err := json.Unmarshal(data, &bindings); err != nil {
+			return err
+		}
+		for seq, action := range bindings {
+			if err := km.Bind(seq, action); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		eq := strings.LastIndex(line, "=")
+		if eq < 0 {
+			return fmt.Errorf("Missing '=' in keymap on line %d", i+1)
+		}
+		seq := strings.TrimSpace(line[:eq])
+		action := strings.TrimSpace(line[eq+1:])
+		if err := km.Bind(seq, action); err != nil {
+			return fmt.Errorf("Line %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Pending returns true if the keys fed so far are the prefix of a longer
+// sequence
+func (km *Keymap) Pending() bool {
+	return len(km.pending) > 0
+}
+
+// Feed adds a key to the sequence being typed. If the sequence is then bound
+// to an action the action is returned and a new sequence is started. If it
+// is the prefix of a longer sequence pending is returned true and more keys
+// are expected. Otherwise the sequence is not bound, an empty action is
+// returned and a new sequence is started.
+//
+// If Timeout has elapsed since the previous key was fed, the sequence it
+// belonged to is abandoned first. See Expire.
+func (km *Keymap) Feed(ev KeyEvent) (action string, pending bool) {
+	now := time.Now()
+	if km.Timeout > 0 && now.Sub(km.last) > km.Timeout {
+		km.pending = nil
+	}
+	km.last = now
+
+	seq := append(km.pending, keyStroke{ev.Key, ev.Modifiers})
+	action, prefix := km.lookup(seq)
+	if prefix {
+		km.pending = seq
+		return "", true
+	}
+	km.pending = nil
+	return action, false
+}
+
+// Expire abandons the sequence being typed, returning the action bound to
+// the keys typed so far or an empty string if there is none. It should be
+// called once Timeout has elapsed without a further key being typed.
+func (km *Keymap) Expire() string {
+	action, _ := km.lookup(km.pending)
+	km.pending = nil
+	return action
+}
+
+// Read reads keys from the window until they complete a bound sequence or a
+// key which is not bound is typed, returning the action and the last event
+// read. The action is empty if the keys are not bound, in which case the
+// application may handle the event itself. If a sequence is abandoned
+// because its timeout expired, the action bound to the keys typed so far is
+// returned, as by Expire, with a nil event. Mouse and resize events are
+// returned as they are read, without an action. An error is returned if no
+// input is available from a window which does not block.
+//
+// While waiting for the next key of a sequence the window's input timeout is
+// set to the keymap's Timeout, as if set by Window.Timeout.
+func (km *Keymap) Read(w *Window) (string, Event, error) {
+	defer w.Timeout(w.Delay())
+	for {
+		ev := readEvent(w)
+		if ev == nil {
+			if km.Pending() {
+				return km.Expire(), nil, nil
+			}
+			return "", nil, errors.New("No input available")
+		}
+		ke, ok := ev.(KeyEvent)
+		if !ok {
+			return "", ev, nil
+		}
+		if action, pending := km.Feed(ke); !pending {
+			return action, ke, nil
+		}
+		if km.Timeout > 0 {
+			w.Timeout(int(km.Timeout / time.Millisecond))
+		}
+	}
+}
+
+// lookup returns the action bound to seq and whether seq is the prefix of a
+// longer sequence. The first keymap in the stack which knows seq decides.
+func (km *Keymap) lookup(seq []keyStroke) (string, bool) {
+	for m := km; m != nil; m = m.Parent {
+		if n := m.root.find(seq); n != nil && (n.action != "" ||
+			n.bound()) {
+			return n.action, n.bound()
+		}
+	}
+	return "", false
+}
+
+func (n *keyNode) find(seq []keyStroke) *keyNode {
+	for _, s := range seq {
+		if n = n.next[s]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// bound returns true if any sequence continuing from n is bound
+func (n *keyNode) bound() bool {
+	for _, next := range n.next {
+		if next.action != "" || next.bound() {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKeySequence parses a space separated sequence of keys. Names in angle
+// brackets may contain spaces, as in "<page down>".
+func parseKeySequence(seq string) ([]keyStroke, error) {
+	var strokes []keyStroke
+	for rest := strings.TrimSpace(seq); rest != ""; {
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if open := strings.Index(rest, "<"); open >= 0 &&
+			(end < 0 || open < end) {
+			if close := strings.Index(rest[open:], ">"); close > 1 {
+				end = open + close + 1
+			}
+		}
+		if end < 0 {
+			end = len(rest)
+		}
+		s, err := parseKeyStroke(rest[:end])
+		if err != nil {
+			return nil, err
+		}
+		strokes = append(strokes, s)
+		rest = strings.TrimSpace(rest[end:])
+	}
+	if len(strokes) == 0 {
+		return nil, errors.New("Empty key sequence")
+	}
+	return strokes, nil
+}
+
+// parseKeyStroke parses a single key. Unlike ParseKey, modifiers are kept
+// apart from the key, in the same way as KeyEvent.
+func parseKeyStroke(s string) (keyStroke, error) {
+	mod, name := splitModifiers(s)
+	if r, ok := singleRune(name); ok {
+		if mod&MOD_CTRL != 0 {
+			if k, ok := controlKey(r); ok {
+				r, mod = rune(k), mod&^MOD_CTRL
+			}
+		}
+		if mod&MOD_SHIFT != 0 && unicode.IsLower(r) {
+			r, mod = unicode.ToUpper(r), mod&^MOD_SHIFT
+		}
+		return keyStroke{Key(r), mod}, nil
+	}
+	k, ok := lookupKeyName(name)
+	if !ok {
+		return keyStroke{}, errors.New("Unknown key name: " + s)
+	}
+	base, m := DecodeKey(k)
+	return keyStroke{base, mod | m}, nil
+}
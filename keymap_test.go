@@ -0,0 +1,136 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func feed(km *goncurses.Keymap, keys ...goncurses.KeyEvent) (string, bool) {
+	var action string
+	var pending bool
+	for _, k := range keys {
+		action, pending = km.Feed(k)
+	}
+	return action, pending
+}
+
+func TestKeymap(t *testing.T) {
+	ctrlX := goncurses.KeyEvent{Key: 0x18, Rune: 0x18}
+	ctrlS := goncurses.KeyEvent{Key: 0x13, Rune: 0x13}
+	ctrlLeft := goncurses.KeyEvent{Key: goncurses.KEY_LEFT,
+		Modifiers: goncurses.MOD_CTRL}
+	altX := goncurses.KeyEvent{Key: 'x', Rune: 'x',
+		Modifiers: goncurses.MOD_ALT}
+	q := goncurses.KeyEvent{Key: 'q', Rune: 'q'}
+
+	global := goncurses.NewKeymap(nil)
+	global.Timeout = 0
+	for seq, action := range map[string]string{
+		"C-x C-s":  "save",
+		"C-<left>": "word-left",
+		"M-x":      "command",
+		"q":        "quit",
+	} {
+		if err := global.Bind(seq, action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	widget := goncurses.NewKeymap(global)
+	widget.Timeout = 0
+	widget.Bind("q", "close")
+
+	tests := []struct {
+		km     *goncurses.Keymap
+		keys   []goncurses.KeyEvent
+		action string
+	}{
+		{global, []goncurses.KeyEvent{ctrlX, ctrlS}, "save"},
+		{global, []goncurses.KeyEvent{ctrlLeft}, "word-left"},
+		{global, []goncurses.KeyEvent{altX}, "command"},
+		{global, []goncurses.KeyEvent{ctrlX, q}, ""},
+		{widget, []goncurses.KeyEvent{q}, "close"},
+		{widget, []goncurses.KeyEvent{ctrlX, ctrlS}, "save"},
+	}
+	for _, test := range tests {
+		if action, _ := feed(test.km, test.keys...); action != test.action {
+			t.Errorf("%v: got %q, want %q", test.keys, action, test.action)
+		}
+	}
+
+	if _, pending := global.Feed(ctrlX); !pending {
+		t.Error("expected C-x to be pending")
+	}
+	if action := global.Expire(); action != "" || global.Pending() {
+		t.Errorf("Expire: got %q, pending %v", action, global.Pending())
+	}
+}
+
+func TestKeymapLoad(t *testing.T) {
+	configs := []string{
+		"# comment\nC-x C-s = save\n<page down> = next\n",
+		`{"C-x C-s": "save", "<page down>": "next"}`,
+	}
+	for _, config := range configs {
+		km := goncurses.NewKeymap(nil)
+		if err := km.Load(strings.NewReader(config)); err != nil {
+			t.Fatal(err)
+		}
+		if action, _ := km.Feed(goncurses.KeyEvent{
+			Key: goncurses.KEY_PAGEDOWN}); action != "next" {
+			t.Errorf("got %q, want next", action)
+		}
+	}
+	km := goncurses.NewKeymap(nil)
+	if err := km.Load(strings.NewReader("C-x C-s save")); err == nil {
+		t.Error("expected error for missing '='")
+	}
+}
+
+func TestMenuDrive(t *testing.T) {
+	scr := newScreen(t, 10, 20)
+	defer scr.End()
+
+	var items []*goncurses.MenuItem
+	for _, name := range []string{"apple", "banana", "cherry"} {
+		item, _ := goncurses.NewItem(name, "")
+		defer item.Free()
+		items = append(items, item)
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+	menu.Post()
+	defer menu.UnPost()
+
+	km := goncurses.NewMenuKeymap(nil)
+	km.Bind("j", "down")
+	km.Bind("x", "quit")
+	for _, ev := range []goncurses.KeyEvent{
+		goncurses.NewKeyEvent(goncurses.KEY_DOWN),
+		goncurses.NewKeyEvent('j'),
+	} {
+		if _, err := menu.Drive(km, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if name := menu.Current(nil).Name(); name != "cherry" {
+		t.Errorf("got %q, want cherry", name)
+	}
+	if action, _ := menu.Drive(km, goncurses.NewKeyEvent('x')); action !=
+		"quit" {
+		t.Errorf("got %q, want quit", action)
+	}
+	menu.Drive(km, goncurses.NewKeyEvent('b'))
+	if name := menu.Current(nil).Name(); name != "banana" {
+		t.Errorf("pattern: got %q, want banana", name)
+	}
+}
@@ -0,0 +1,329 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+// #include <curses.h>
+// #include <term.h>
+import "C"
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modifier is a set of modifier keys held down while a key was pressed
+type Modifier byte
+
+const (
+	MOD_SHIFT Modifier = 1 << iota // shift key
+	MOD_ALT                        // alt (meta) key
+	MOD_CTRL                       // control key
+)
+
+// modifiedKeyCaps are the names of the extended terminfo capabilities
+// describing modified cursor and editing keys. xterm and compatible
+// terminals append a digit from 2 to 8 to the name, which is one more than
+// the set of modifiers held. For example kUP5 is control-up.
+var modifiedKeyCaps = []struct {
+	name string
+	key  Key
+}{
+	{"kUP", KEY_UP},
+	{"kDN", KEY_DOWN},
+	{"kLFT", KEY_LEFT},
+	{"kRIT", KEY_RIGHT},
+	{"kHOM", KEY_HOME},
+	{"kEND", KEY_END},
+	{"kIC", KEY_IC},
+	{"kDC", KEY_DC},
+	{"kNXT", KEY_PAGEDOWN},
+	{"kPRV", KEY_PAGEUP},
+}
+
+// shiftedKeys are the standard key codes of shifted keys. Terminals of the
+// xterm family send scroll-forward and scroll-backward for shift-down and
+// shift-up, and back-tab for shift-tab.
+var shiftedKeys = map[Key]Key{
+	KEY_SLEFT:     KEY_LEFT,
+	KEY_SRIGHT:    KEY_RIGHT,
+	KEY_SR:        KEY_UP,
+	KEY_SF:        KEY_DOWN,
+	KEY_SHOME:     KEY_HOME,
+	KEY_SEND:      KEY_END,
+	KEY_SIC:       KEY_IC,
+	KEY_SDC:       KEY_DC,
+	KEY_SNEXT:     KEY_PAGEDOWN,
+	KEY_SPREVIOUS: KEY_PAGEUP,
+	KEY_BTAB:      KEY_TAB,
+}
+
+// xtermModified matches an xterm control sequence carrying modifiers, such
+// as "\x1b[1;2P" (shift-F1) or "\x1b[15;5~" (control-F5)
+var xtermModified = regexp.MustCompile(`^\x1b\[[0-9]*;([2-8])[~A-Za-z]$`)
+
+// DecodeKey splits a key code returned by GetChar, or a KeyEvent's Key, into
+// the unmodified key and the modifiers which were held. For example
+// control-left arrow is returned as KEY_LEFT and MOD_CTRL, and on terminals
+// of the xterm family F13 is returned as KEY_F1 and MOD_SHIFT. Keys which
+// do not carry modifiers are returned unchanged.
+//
+// Modified cursor and editing keys are recognized using the terminal's
+// extended capabilities (kUP5, kLFT3 and so on) and so require a terminal
+// description which defines them.
+func DecodeKey(k Key) (Key, Modifier) {
+	if base, ok := shiftedKeys[k]; ok {
+		return base, MOD_SHIFT
+	}
+	if k >= KEY_F1+12 && k < KEY_F1+63 {
+		m := xtermModified.FindStringSubmatch(KeyBound(k))
+		if m == nil {
+			return k, 0
+		}
+		mod, _ := strconv.Atoi(m[1])
+		return KEY_F1 + (k-KEY_F1)%12, Modifier(mod - 1)
+	}
+	if k <= KEY_MAX {
+		return k, 0
+	}
+	ti := CurrentTerminfo()
+	if ti == nil {
+		return k, 0
+	}
+	if s, ok := ti.modifiedKeys()[k]; ok {
+		return s.key, s.mod
+	}
+	return k, 0
+}
+
+// modifiedKeyTables records, for each terminal, the unmodified key and
+// modifiers of the key codes of its modified cursor and editing keys
+var modifiedKeyTables = make(map[*C.TERMINAL]map[Key]keyStroke)
+
+// modifiedKeys returns the key and modifiers of each key code the terminal
+// defines with modifiedKeyCaps. The table is built the first time it is
+// needed, but not kept while ncurses has yet to give the sequences key codes,
+// as it does once Keypad is first enabled.
+func (t *Terminfo) modifiedKeys() map[Key]keyStroke {
+	if keys, ok := modifiedKeyTables[t.term]; ok {
+		return keys
+	}
+	keys := make(map[Key]keyStroke)
+	for _, c := range modifiedKeyCaps {
+		for mod := 2; mod <= 8; mod++ {
+			seq, ok := t.String(c.name + strconv.Itoa(mod))
+			if k := KeyDefined(seq); ok && k > 0 {
+				keys[k] = keyStroke{c.key, Modifier(mod - 1)}
+			}
+		}
+	}
+	if len(keys) > 0 {
+		modifiedKeyTables[t.term] = keys
+	}
+	return keys
+}
+
+// NewKeyEvent returns the KeyEvent for a key code or character returned by
+// GetChar, with modifiers decoded as an EventLoop would deliver it
+func NewKeyEvent(k Key) KeyEvent {
+	if k < C.KEY_MIN {
+		return KeyEvent{Key: k, Rune: rune(k)}
+	}
+	key, mod := DecodeKey(k)
+	return KeyEvent{Key: key, Modifiers: mod}
+}
+
+// encodeKey returns the key code the terminal sends for base pressed with
+// the given modifiers. It is the reverse of DecodeKey.
+func encodeKey(base Key, mod Modifier) (Key, bool) {
+	if mod == MOD_SHIFT {
+		for k, b := range shiftedKeys {
+			if b == base {
+				return k, true
+			}
+		}
+	}
+	if base >= KEY_F1 && base < KEY_F1+12 {
+		for k := base + 12; k < KEY_F1+63; k += 12 {
+			if b, m := DecodeKey(k); b == base && m == mod {
+				return k, true
+			}
+		}
+		return 0, false
+	}
+	ti := CurrentTerminfo()
+	if ti == nil {
+		return 0, false
+	}
+	for k, s := range ti.modifiedKeys() {
+		if s.key == base && s.mod == mod {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// modPrefix returns the prefix used by KeyString for the modifiers
+func modPrefix(mod Modifier) string {
+	var b strings.Builder
+	if mod&MOD_CTRL != 0 {
+		b.WriteString("C-")
+	}
+	if mod&MOD_ALT != 0 {
+		b.WriteString("M-")
+	}
+	if mod&MOD_SHIFT != 0 {
+		b.WriteString("S-")
+	}
+	return b.String()
+}
+
+var fkeyName = regexp.MustCompile(`^F\(?([0-9]+)\)?$`)
+
+// keyName returns the name ncurses gives the key code k, without the "KEY_"
+// prefix, for example "DC" or "F13"
+func keyName(k Key) (string, bool) {
+	name := C.keyname(C.int(k))
+	if name == nil {
+		return "", false
+	}
+	s := strings.TrimPrefix(C.GoString(name), "KEY_")
+	if s == "UNKNOWN KEY" {
+		return "", false
+	}
+	if m := fkeyName.FindStringSubmatch(s); m != nil {
+		s = "F" + m[1]
+	}
+	return s, true
+}
+
+var keyNames struct {
+	once  sync.Once
+	names map[string]Key
+}
+
+// lookupKeyName returns the key code with the given name, as returned by
+// KeyString, ignoring case
+func lookupKeyName(name string) (Key, bool) {
+	keyNames.once.Do(func() {
+		keyNames.names = map[string]Key{"space": ' '}
+		for k := Key(C.KEY_MAX); k >= C.KEY_MIN; k-- {
+			if s, ok := keyName(k); ok {
+				keyNames.names[strings.ToLower(s)] = k
+			}
+		}
+		for k, s := range keyList {
+			s = strings.ToLower(s)
+			if old, ok := keyNames.names[s]; !ok || k < old {
+				keyNames.names[s] = k
+			}
+		}
+	})
+	if k, ok := keyNames.names[strings.ToLower(name)]; ok {
+		return k, true
+	}
+	if m := fkeyName.FindStringSubmatch(strings.ToUpper(name)); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err == nil && n <= 63 {
+			return Key(C.KEY_F0 + n), true
+		}
+	}
+	// extended capabilities, such as kUP5, are only known to the terminal
+	if ti := CurrentTerminfo(); ti != nil && strings.HasPrefix(name, "k") {
+		if seq, ok := ti.String(name); ok {
+			if k := KeyDefined(seq); k > 0 {
+				return k, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// ParseKey returns the key code named by s. It accepts the names returned by
+// KeyString, optionally enclosed in angle brackets, so that names read from
+// configuration files such as "q", "C-x", "<F5>", "<page down>" or
+// "C-<left>" can be turned back into the codes returned by GetChar. Names
+// of more than one character are not case sensitive.
+//
+// The "C-" prefix may be used with any letter or with one of @[\]^_? to
+// name a control character. Modifiers on function keys can only be parsed
+// when the current terminal defines a key code for the combination, and
+// once Keypad has been enabled on a window.
+func ParseKey(s string) (Key, error) {
+	mod, name := splitModifiers(s)
+	if r, ok := singleRune(name); ok {
+		switch mod {
+		case 0:
+			return Key(r), nil
+		case MOD_CTRL:
+			if k, ok := controlKey(r); ok {
+				return k, nil
+			}
+		}
+		return 0, errors.New("Key can not be represented: " + s)
+	}
+
+	base, ok := lookupKeyName(name)
+	if !ok {
+		return 0, errors.New("Unknown key name: " + s)
+	}
+	if mod == 0 {
+		return base, nil
+	}
+	if k, ok := encodeKey(base, mod); ok {
+		return k, nil
+	}
+	return 0, errors.New("Key can not be represented: " + s)
+}
+
+// splitModifiers splits the "C-", "M-" (or "A-") and "S-" prefixes from a
+// key name, also removing any angle brackets around the rest of the name
+func splitModifiers(s string) (Modifier, string) {
+	var mod Modifier
+	name := s
+prefix:
+	for len(name) > 2 && name[1] == '-' {
+		switch name[0] {
+		case 'C':
+			mod |= MOD_CTRL
+		case 'M', 'A':
+			mod |= MOD_ALT
+		case 'S':
+			mod |= MOD_SHIFT
+		default:
+			break prefix
+		}
+		name = name[2:]
+	}
+	if len(name) > 2 && name[0] == '<' && name[len(name)-1] == '>' {
+		name = name[1 : len(name)-1]
+	}
+	return mod, name
+}
+
+// singleRune returns the rune making up s if s is a single character
+func singleRune(s string) (rune, bool) {
+	r, size := utf8.DecodeRuneInString(s)
+	return r, size == len(s) && r != utf8.RuneError
+}
+
+// controlKey returns the control character typed by holding control and
+// pressing r
+func controlKey(r rune) (Key, bool) {
+	switch {
+	case r == '?':
+		return 127, true
+	case r >= '@' && r <= '_':
+		return Key(r & 0x1f), true
+	case r >= 'a' && r <= 'z':
+		return Key(unicode.ToUpper(r) & 0x1f), true
+	}
+	return 0, false
+}
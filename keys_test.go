@@ -0,0 +1,104 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestDefineKey(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	const seq, code = "\x1b[99~", goncurses.KEY_MAX + 100
+	if err := goncurses.DefineKey(seq, code); err != nil {
+		t.Fatal(err)
+	}
+	if k := goncurses.KeyDefined(seq); k != code {
+		t.Errorf("KeyDefined: got %d, want %d", k, code)
+	}
+	if s := goncurses.KeyBound(code); s != seq {
+		t.Errorf("KeyBound: got %q, want %q", s, seq)
+	}
+	scr.Send(seq)
+	if k := w.GetChar(); k != code {
+		t.Errorf("GetChar: got %d, want %d", k, code)
+	}
+
+	if k, mod := goncurses.DecodeKey(goncurses.KeyDefined("\x1b[1;7C")); k !=
+		goncurses.KEY_RIGHT || mod != goncurses.MOD_CTRL|goncurses.MOD_ALT {
+		t.Errorf("DecodeKey: got %d, %d", k, mod)
+	}
+	// rebinding a modified key's sequence is seen by DecodeKey
+	if err := goncurses.DefineKey("\x1b[1;7C", code+1); err != nil {
+		t.Fatal(err)
+	}
+	if k, mod := goncurses.DecodeKey(code + 1); k != goncurses.KEY_RIGHT ||
+		mod != goncurses.MOD_CTRL|goncurses.MOD_ALT {
+		t.Errorf("DecodeKey after DefineKey: got %d, %d", k, mod)
+	}
+
+	if err := goncurses.KeyOk(code, false); err != nil {
+		t.Fatal(err)
+	}
+	scr.Send(seq)
+	if k := w.GetChar(); k != goncurses.KEY_ESC {
+		t.Errorf("GetChar with key disabled: got %d, want escape", k)
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+	scr.Stdscr().Keypad(true)
+
+	tests := []struct {
+		key  goncurses.Key
+		name string
+	}{
+		{'a', "a"},
+		{'é', "é"},
+		{0x18, "C-x"},
+		{0, "C-@"},
+		{goncurses.KEY_ESC, "esc"},
+		{goncurses.KEY_DOWN, "down"},
+		{goncurses.KEY_F5, "F5"},
+		{goncurses.KEY_DC, "DC"},
+		{goncurses.KEY_SLEFT, "S-left"},
+		{goncurses.KEY_F1 + 14, "S-F3"},
+		{goncurses.KeyDefined("\x1b[1;5D"), "C-left"},
+	}
+	for _, test := range tests {
+		if s := goncurses.KeyString(test.key); s != test.name {
+			t.Errorf("KeyString(%d): got %q, want %q", test.key, s, test.name)
+		}
+		if k, err := goncurses.ParseKey(test.name); err != nil ||
+			k != test.key {
+			t.Errorf("ParseKey(%q): got %d, %v; want %d", test.name, k,
+				err, test.key)
+		}
+	}
+
+	aliases := map[string]goncurses.Key{
+		"<F5>":        goncurses.KEY_F5,
+		"<page down>": goncurses.KEY_PAGEDOWN,
+		"C-<left>":    goncurses.KeyDefined("\x1b[1;5D"),
+	}
+	for name, key := range aliases {
+		if k, err := goncurses.ParseKey(name); err != nil || k != key {
+			t.Errorf("ParseKey(%q): got %d, %v; want %d", name, k, err, key)
+		}
+	}
+	for _, name := range []string{"bogus", "M-x"} {
+		if _, err := goncurses.ParseKey(name); err == nil {
+			t.Errorf("ParseKey(%q): expected error", name)
+		}
+	}
+}
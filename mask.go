@@ -0,0 +1,399 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// #include <form.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// fieldMask is the input mask of a field, see SetMask
+type fieldMask struct {
+	pattern string
+	slots   []rune // the class of each column, or 0 for a literal
+	blank   []rune // the contents of the field with every slot empty
+	typ     *FieldType
+}
+
+// fieldMasks are the masks set on fields, by field
+var fieldMasks = make(map[*Field]*fieldMask)
+
+// parseMask returns the mask described by pattern, see SetMask
+func parseMask(pattern string) (*fieldMask, error) {
+	m := &fieldMask{pattern: pattern}
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+			continue
+		case maskClass(r):
+			m.slots = append(m.slots, r)
+			m.blank = append(m.blank, ' ')
+			continue
+		}
+		if !unicode.IsPrint(r) {
+			return nil, fmt.Errorf("Invalid character %q in mask", r)
+		}
+		m.slots = append(m.slots, 0)
+		m.blank = append(m.blank, r)
+	}
+	if escaped {
+		return nil, errors.New("Mask ends with an escape")
+	}
+	if m.next(0) == len(m.slots) {
+		return nil, errors.New("Mask has no characters to enter")
+	}
+	return m, nil
+}
+
+// maskClass returns true if r stands for a character entered in a mask
+func maskClass(r rune) bool {
+	switch r {
+	case '#', 'Y', 'M', 'D', 'A', 'X', '?':
+		return true
+	}
+	return false
+}
+
+// accepts returns true if r may be entered in a slot of the class
+func (m *fieldMask) accepts(class, r rune) bool {
+	switch class {
+	case '#', 'Y', 'M', 'D':
+		return r >= '0' && r <= '9'
+	case 'A':
+		return unicode.IsLetter(r)
+	case 'X':
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	case '?':
+		return unicode.IsPrint(r) && r != ' '
+	}
+	return false
+}
+
+// next returns the first slot at or after column col, or the length of the
+// mask if there is none
+func (m *fieldMask) next(col int) int {
+	for ; col < len(m.slots) && m.slots[col] == 0; col++ {
+	}
+	return col
+}
+
+// prev returns the last slot before column col, or -1 if there is none
+func (m *fieldMask) prev(col int) int {
+	for col--; col >= 0 && m.slots[col] == 0; col-- {
+	}
+	return col
+}
+
+// contents returns the runes of the field's buffer, one for each column of
+// the mask
+func (m *fieldMask) contents(f *Field) []rune {
+	buf := []rune(f.Buffer())
+	for len(buf) < len(m.slots) {
+		buf = append(buf, ' ')
+	}
+	return buf[:len(m.slots)]
+}
+
+// validField checks that every slot of the field is filled with a character
+// its class accepts, or that every slot is empty and the field may be left
+// blank
+func (m *fieldMask) validField(f *Field) bool {
+	filled, empty := true, true
+	contents := m.contents(f)
+	for i, r := range contents {
+		switch {
+		case m.slots[i] == 0:
+			filled = filled && r == m.blank[i]
+		case r == ' ':
+			filled = false
+		default:
+			empty = false
+			filled = filled && m.accepts(m.slots[i], r)
+		}
+	}
+	if filled {
+		return m.validDate(contents)
+	}
+	return empty && f.Opts()&FO_NULLOK != 0
+}
+
+// validDate checks that the month and day entered in the mask's M and D
+// slots, if it has them, are a valid date, in the year entered in its Y
+// slots or else in a leap year
+func (m *fieldMask) validDate(contents []rune) bool {
+	digits := make(map[rune]string)
+	for i, class := range m.slots {
+		switch class {
+		case 'Y', 'M', 'D':
+			digits[class] += string(contents[i])
+		}
+	}
+	year, month, day := 2000, 1, 1
+	for class, p := range map[rune]*int{'Y': &year, 'M': &month, 'D': &day} {
+		if s, ok := digits[class]; ok {
+			*p, _ = strconv.Atoi(s)
+		}
+	}
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	// the day after the last of the month is normalized to the next month
+	last := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC)
+	return day <= last.Day()
+}
+
+// validChar checks that some slot of the mask accepts r
+func (m *fieldMask) validChar(r rune) bool {
+	for _, class := range m.slots {
+		if class != 0 && m.accepts(class, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetMask sets an input mask on the field, such as "(###) ###-####" or
+// "YYYY-MM-DD". Each of the following characters of the mask stands for a
+// character to be entered:
+//
+//	#         a digit
+//	Y M D     a digit of the year, month or day of a date
+//	A         a letter
+//	X         a letter or digit
+//	?         any character but a blank
+//
+// Every other character is a literal shown in the field, which may be
+// included in the mask by preceding it with a backslash. When the form is
+// driven with Form.Drive, characters typed go to the next position the
+// cursor can enter, skipping the literals, and are refused unless they are
+// accepted there; backspace and delete empty a position rather than
+// moving the rest of the text. The field is only valid when every position
+// is filled, or when all are empty and the field has FO_NULLOK set, and
+// the month and day entered in its M and D positions are those of a date.
+//
+// The mask replaces the field's contents and type, and turns off FO_PASSOK
+// so that the field is validated whenever it is left. It must fit on the
+// field, which must be static and of a single line. Raw returns the
+// characters entered without the literals, while Buffer and Text return
+// the field as displayed. An empty mask removes the field's mask.
+func (f *Field) SetMask(mask string) error {
+	old := fieldMasks[f]
+	if mask == "" {
+		if old == nil {
+			return nil
+		}
+		if err := f.SetType(nil); err != nil {
+			return err
+		}
+		delete(fieldMasks, f)
+		return old.typ.Free()
+	}
+	m, err := parseMask(mask)
+	if err != nil {
+		return err
+	}
+	rows, cols, _, err := f.DynamicInfo()
+	if err != nil {
+		return err
+	}
+	if rows != 1 || f.Dynamic() {
+		return errors.New("Masks require a static field of one line")
+	}
+	if len(m.slots) > cols {
+		return errors.New("Mask is wider than the field")
+	}
+	if m.typ, err = NewFieldType(m.validField, m.validChar); err != nil {
+		return err
+	}
+	if err := f.SetType(m.typ); err != nil {
+		m.typ.Free()
+		return err
+	}
+	// the contents are replaced rather than edited by the form driver, so the
+	// form does not know to validate the field unless it always does
+	f.Options(FO_PASSOK, false)
+	fieldMasks[f] = m
+	if old != nil {
+		old.typ.Free()
+	}
+	return f.SetBuffer(string(m.blank))
+}
+
+// Mask returns the field's input mask, or an empty string if it has none
+func (f *Field) Mask() string {
+	if m := fieldMasks[f]; m != nil {
+		return m.pattern
+	}
+	return ""
+}
+
+// Raw returns the characters entered in a field with an input mask,
+// without the literals of the mask, such as "5551234567" for a field
+// displaying "(555) 123-4567". It returns the same as Text for a field
+// without a mask.
+func (f *Field) Raw() string {
+	m := fieldMasks[f]
+	if m == nil {
+		return f.Text()
+	}
+	var raw []rune
+	for i, r := range m.contents(f) {
+		if m.slots[i] != 0 && r != ' ' {
+			raw = append(raw, r)
+		}
+	}
+	return string(raw)
+}
+
+// SetRaw fills the positions of a field with an input mask with the
+// characters of raw, in order, leaving any which remain empty. An error is
+// returned, and the field left unchanged, if a character is not accepted
+// by the mask or there are too many.
+func (f *Field) SetRaw(raw string) error {
+	m := fieldMasks[f]
+	if m == nil {
+		return errors.New("Field has no mask")
+	}
+	buf := append([]rune(nil), m.blank...)
+	col := 0
+	for _, r := range raw {
+		if col = m.next(col); col == len(buf) {
+			return errors.New("Too many characters for mask")
+		}
+		if !m.accepts(m.slots[col], r) {
+			return fmt.Errorf("Mask does not accept %q at column %d", r,
+				col)
+		}
+		buf[col] = r
+		col++
+	}
+	return f.SetBuffer(string(buf))
+}
+
+// drive performs the action, or enters the character typed, on the current
+// field of the form, which has the mask. It returns false if the action is
+// left to the form driver.
+func (m *fieldMask) drive(f *Form, action string, ev KeyEvent) (bool, error) {
+	field := (*Field)(C.current_field(f.form))
+	_, col := f.cursor()
+	buf := m.contents(field)
+	denied := errors.New(errList[C.E_REQUEST_DENIED])
+
+	switch action {
+	case "":
+		if ev.Modifiers != 0 || !unicode.IsPrint(ev.Rune) {
+			return false, nil
+		}
+		pos := m.next(col)
+		if pos == len(buf) || !m.accepts(m.slots[pos], ev.Rune) {
+			return true, denied
+		}
+		buf[pos] = ev.Rune
+		next := m.next(pos + 1)
+		if err := m.update(f, field, buf, next); err != nil {
+			return true, err
+		}
+		if next == len(buf) && field.Opts()&FO_AUTOSKIP != 0 {
+			return true, f.Driver(REQ_NEXT_FIELD)
+		}
+		return true, nil
+	case "del-prev":
+		pos := m.prev(col)
+		if pos < 0 {
+			return true, denied
+		}
+		buf[pos] = ' '
+		return true, m.update(f, field, buf, pos)
+	case "del-char":
+		if col >= len(buf) || m.slots[col] == 0 {
+			return true, denied
+		}
+		buf[col] = ' '
+		return true, m.update(f, field, buf, col)
+	case "clr-field", "clr-eol", "clr-eof":
+		from := 0
+		if action != "clr-field" {
+			from = col
+		}
+		for i := from; i < len(buf); i++ {
+			if m.slots[i] != 0 {
+				buf[i] = ' '
+			}
+		}
+		return true, m.update(f, field, buf, m.next(from))
+	case "prev-char", "left-char":
+		pos := m.prev(col)
+		if pos < 0 {
+			return true, denied
+		}
+		return true, m.moveTo(f, pos)
+	case "next-char", "right-char":
+		if col >= len(buf) {
+			return true, denied
+		}
+		return true, m.moveTo(f, m.next(col+1))
+	case "beg-field", "beg-line":
+		return true, m.moveTo(f, m.next(0))
+	case "end-field", "end-line":
+		end := 0
+		for i, r := range buf {
+			if m.slots[i] != 0 && r != ' ' {
+				end = i + 1
+			}
+		}
+		return true, m.moveTo(f, m.next(end))
+	case "ins-char", "ins-line", "del-line", "del-word":
+		return true, denied
+	}
+	return false, nil
+}
+
+// update replaces the contents of the current field, marking it changed,
+// and moves the cursor to column col
+func (m *fieldMask) update(f *Form, field *Field, buf []rune, col int) error {
+	if err := field.SetBuffer(string(buf)); err != nil {
+		return err
+	}
+	field.SetStatus(true)
+	return m.moveTo(f, col)
+}
+
+// moveTo moves the cursor to column col of the current field, or to its
+// last column if col is past the end
+func (m *fieldMask) moveTo(f *Form, col int) error {
+	_, cols, _, _, _, _, err := f.Current().Info()
+	if err != nil {
+		return err
+	}
+	if col > cols-1 {
+		col = cols - 1
+	}
+	for {
+		_, cur := f.cursor()
+		if cur == col {
+			return nil
+		}
+		req := REQ_NEXT_CHAR
+		if cur > col {
+			req = REQ_PREV_CHAR
+		}
+		if err := f.Driver(Key(req)); err != nil {
+			return err
+		}
+	}
+}
@@ -7,7 +7,7 @@
 package goncurses
 
 /*
-#cgo !darwin,!openbsd pkg-config: menu
+#cgo !darwin,!openbsd pkg-config: menuw
 #cgo darwin openbsd LDFLAGS: -lmenu
 #include <menu.h>
 #include <stdlib.h>
@@ -18,6 +18,7 @@ ITEM* menu_item_at(ITEM** ilist, int i) {
 import "C"
 
 import (
+	"errors"
 	"syscall"
 	"unsafe"
 )
@@ -61,16 +62,19 @@ func RequestByName(request string) (res int, err error) {
 
 // Background returns the menu's background character setting
 func (m *Menu) Background() int {
+	checkDispatch()
 	return int(C.menu_back(m.menu))
 }
 
 // Count returns the number of MenuItems in the Menu
 func (m *Menu) Count() int {
+	checkDispatch()
 	return int(C.item_count(m.menu))
 }
 
 // Current returns the selected item in the menu
 func (m *Menu) Current(mi *MenuItem) *MenuItem {
+	checkDispatch()
 	if mi == nil {
 		return &MenuItem{C.current_item(m.menu)}
 	}
@@ -81,17 +85,80 @@ func (m *Menu) Current(mi *MenuItem) *MenuItem {
 // Driver controls how the menu is activated. Action usually corresponds
 // to the string returned by the Key() function in goncurses.
 func (m *Menu) Driver(daction MenuDriverReq) error {
+	checkDispatch()
 	err := C.menu_driver(m.menu, C.int(daction))
 	return ncursesError(syscall.Errno(err))
 }
 
+// Drive feeds the key event to the keymap and, if the keys typed are bound
+// to one of the MenuActions, performs the corresponding request. The action
+// is returned so that the application can handle any other actions itself.
+// Printable characters which are not bound are passed to the menu to
+// search the item names, see Pattern.
+func (m *Menu) Drive(km *Keymap, ev KeyEvent) (string, error) {
+	checkDispatch()
+	action, pending := km.Feed(ev)
+	if req, ok := MenuActions[action]; ok {
+		return action, m.Driver(req)
+	}
+	if action == "" && !pending && ev.Modifiers == 0 && ev.Rune > ' ' &&
+		ev.Rune < 0x7f {
+		return "", m.Driver(MenuDriverReq(ev.Rune))
+	}
+	return action, nil
+}
+
+// HandleMouse performs the menu requests for a mouse event reported at
+// screen coordinates, such as one delivered by an EventLoop. Clicking or
+// pressing the first button over an item makes it the current item and, if
+// O_ONEVALUE is off, a double click also toggles it. Turning the wheel over
+// the menu scrolls it up or down a line. An E_REQUEST_DENIED error is
+// returned for any other event or position.
+func (m *Menu) HandleMouse(me *MouseEvent) error {
+	checkDispatch()
+	if C.menu_opts(m.menu)&C.O_ONEVALUE == 0 &&
+		me.Button == 1 && me.Action == MOUSE_DOUBLE_CLICK {
+		if err := m.selectItemAt(me.Y, me.X); err != nil {
+			return err
+		}
+		return m.Driver(REQ_TOGGLE)
+	}
+	switch {
+	case me.Action == MOUSE_WHEEL && (me.Button == 4 || me.Button == 5):
+		win, _ := m.windows()
+		if !C.wenclose(win, C.int(me.Y), C.int(me.X)) {
+			break
+		}
+		if me.Button == 4 {
+			return m.Driver(REQ_ULINE)
+		}
+		return m.Driver(REQ_DLINE)
+	case me.Button == 1 && me.Action >= MOUSE_PRESS &&
+		me.Action <= MOUSE_TRIPLE_CLICK && me.Action != MOUSE_RELEASE:
+		return m.selectItemAt(me.Y, me.X)
+	}
+	return errors.New(errList[C.E_REQUEST_DENIED])
+}
+
+// selectItemAt makes the item at the screen position y, x current
+func (m *Menu) selectItemAt(y, x int) error {
+	item := m.itemAt(y, x)
+	if item == nil {
+		return errors.New(errList[C.E_REQUEST_DENIED])
+	}
+	err := C.set_current_item(m.menu, item)
+	return ncursesError(syscall.Errno(err))
+}
+
 // Foreground gets the attributes of highlighted items in the menu
 func (m *Menu) Foreground() int {
+	checkDispatch()
 	return int(C.menu_fore(m.menu))
 }
 
 // Format sets the menu format. See the O_* menu options.
 func (m *Menu) Format(r, c int) error {
+	checkDispatch()
 	err := C.set_menu_format(m.menu, C.int(r), C.int(c))
 	return ncursesError(syscall.Errno(err))
 }
@@ -99,18 +166,22 @@ func (m *Menu) Format(r, c int) error {
 // Free deallocates memory set aside for the menu. This must be called
 // before exiting.
 func (m *Menu) Free() error {
+	checkDispatch()
 	err := C.free_menu(m.menu)
+	unposted(m)
 	m = nil
 	return ncursesError(syscall.Errno(err))
 }
 
 // Grey sets the attributes of non-selectable items in the menu
 func (m *Menu) Grey(ch Char) {
+	checkDispatch()
 	C.set_menu_grey(m.menu, C.chtype(ch))
 }
 
 // Items will return the items in the menu.
 func (m *Menu) Items() []*MenuItem {
+	checkDispatch()
 	citems := C.menu_items(m.menu)
 	count := m.Count()
 	mitems := make([]*MenuItem, count)
@@ -122,6 +193,7 @@ func (m *Menu) Items() []*MenuItem {
 
 // Mark sets the indicator for the currently selected menu item
 func (m *Menu) Mark(mark string) error {
+	checkDispatch()
 	cmark := C.CString(mark)
 	defer C.free(unsafe.Pointer(cmark))
 
@@ -132,6 +204,7 @@ func (m *Menu) Mark(mark string) error {
 // Option sets the options for the menu. See the O_* definitions for
 // a list of values which can be OR'd together
 func (m *Menu) Option(opts int, on bool) error {
+	checkDispatch()
 	var err C.int
 	if on {
 		err = C.menu_opts_on(m.menu, C.Menu_Options(opts))
@@ -143,27 +216,35 @@ func (m *Menu) Option(opts int, on bool) error {
 
 // Pad sets the padding character for menu items.
 func (m *Menu) Pad() int {
+	checkDispatch()
 	return int(C.menu_pad(m.menu))
 }
 
 // Pattern returns the menu's pattern buffer
 func (m *Menu) Pattern() string {
+	checkDispatch()
 	return C.GoString(C.menu_pattern(m.menu))
 }
 
 // PositionCursor sets the cursor over the currently selected menu item.
 func (m *Menu) PositionCursor() {
+	checkDispatch()
 	C.pos_menu_cursor(m.menu)
 }
 
 // Post the menu, making it visible
 func (m *Menu) Post() error {
+	checkDispatch()
 	err := C.post_menu(m.menu)
+	if err == C.E_OK {
+		posted(m)
+	}
 	return ncursesError(syscall.Errno(err))
 }
 
 // Scale
 func (m *Menu) Scale() (int, int, error) {
+	checkDispatch()
 	var y, x C.int
 	err := C.scale_menu(m.menu, (*C.int)(&y), (*C.int)(&x))
 	return int(y), int(x), ncursesError(syscall.Errno(err))
@@ -172,12 +253,14 @@ func (m *Menu) Scale() (int, int, error) {
 // SetBackground set the attributes of the un-highlighted items in the
 // menu
 func (m *Menu) SetBackground(ch Char) error {
+	checkDispatch()
 	err := C.set_menu_back(m.menu, C.chtype(ch))
 	return ncursesError(syscall.Errno(err))
 }
 
 // SetForeground sets the attributes of the highlighted items in the menu
 func (m *Menu) SetForeground(ch Char) error {
+	checkDispatch()
 	err := C.set_menu_fore(m.menu, C.chtype(ch))
 	return ncursesError(syscall.Errno(err))
 }
@@ -185,6 +268,7 @@ func (m *Menu) SetForeground(ch Char) error {
 // SetItems will either set the items in the menu. When setting
 // items you must make sure the prior menu items will be freed.
 func (m *Menu) SetItems(items []*MenuItem) error {
+	checkDispatch()
 	citems := make([]*C.ITEM, len(items)+1)
 	for index, item := range items {
 		citems[index] = item.item
@@ -196,12 +280,14 @@ func (m *Menu) SetItems(items []*MenuItem) error {
 
 // SetPad sets the padding character for menu items.
 func (m *Menu) SetPad(ch Char) error {
+	checkDispatch()
 	err := C.set_menu_pad(m.menu, C.int(ch))
 	return ncursesError(syscall.Errno(err))
 }
 
 // SetPattern sets the padding character for menu items.
 func (m *Menu) SetPattern(pattern string) error {
+	checkDispatch()
 	cpattern := C.CString(pattern)
 	defer C.free(unsafe.Pointer(cpattern))
 	err := C.set_menu_pattern(m.menu, (*C.char)(cpattern))
@@ -215,6 +301,7 @@ func (m *Menu) SetPattern(pattern string) error {
 // multi-column mode. Use values of 0 or 1 to reset spacing to default,
 // which is one
 func (m *Menu) SetSpacing(desc, row, col int) error {
+	checkDispatch()
 	err := C.set_menu_spacing(m.menu, C.int(desc), C.int(row),
 		C.int(col))
 	return ncursesError(syscall.Errno(err))
@@ -222,12 +309,14 @@ func (m *Menu) SetSpacing(desc, row, col int) error {
 
 // SetWindow container for the menu
 func (m *Menu) SetWindow(w *Window) error {
+	checkDispatch()
 	err := C.set_menu_win(m.menu, w.win)
 	return ncursesError(syscall.Errno(err))
 }
 
 // Spacing returns the menu item spacing. See SetSpacing for a description
 func (m *Menu) Spacing() (int, int, int) {
+	checkDispatch()
 	var desc, row, col C.int
 	C.menu_spacing(m.menu, (*C.int)(&desc), (*C.int)(&row),
 		(*C.int)(&col))
@@ -237,18 +326,22 @@ func (m *Menu) Spacing() (int, int, int) {
 
 // SubWindow for the menu
 func (m *Menu) SubWindow(sub *Window) error {
+	checkDispatch()
 	err := C.set_menu_sub(m.menu, sub.win)
 	return ncursesError(syscall.Errno(err))
 }
 
 // UnPost the menu, effectively hiding it.
 func (m *Menu) UnPost() error {
+	checkDispatch()
 	err := C.unpost_menu(m.menu)
+	unposted(m)
 	return ncursesError(syscall.Errno(err))
 }
 
 // Window container for the menu. Returns nil on failure
 func (m *Menu) Window() *Window {
+	checkDispatch()
 	return &Window{C.menu_win(m.menu)}
 }
 
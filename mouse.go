@@ -5,21 +5,111 @@
 
 package goncurses
 
-// #cgo !darwin,!openbsd,!windows pkg-config: ncurses
-// #cgo windows CFLAGS: -DNCURSES_MOUSE_VERSION
+// #cgo !darwin,!openbsd,!windows pkg-config: ncursesw
+// #cgo windows CFLAGS: -DNCURSES_MOUSE_VERSION -DPDC_WIDE
 // #cgo windows LDFLAGS: -lpdcurses
 // #include <curses.h>
 // #include "goncurses.h"
 import "C"
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
 	"unsafe"
 )
 
+// MouseEvent describes a mouse event. State holds the raw ncurses button
+// state, which is decoded into Button, Action and Modifiers.
 type MouseEvent struct {
 	Id      int16       /* device ID */
 	X, Y, Z int         /* event coordinates */
 	State   MouseButton /* button state */
+
+	Button    int         // button number, or zero (0) if none
+	Action    MouseAction // what the button or mouse did
+	Modifiers Modifier    // modifier keys held
+}
+
+// MouseAction is what happened in a MouseEvent
+type MouseAction int
+
+const (
+	MOUSE_PRESS        MouseAction = iota + 1 // button pressed
+	MOUSE_RELEASE                             // button released
+	MOUSE_CLICK                               // button clicked
+	MOUSE_DOUBLE_CLICK                        // button double clicked
+	MOUSE_TRIPLE_CLICK                        // button triple clicked
+	MOUSE_DRAG                                // moved with Button held
+	MOUSE_MOVE                                // moved, button unknown or none
+	MOUSE_WHEEL                               // wheel up (4) or down (5)
+)
+
+// mouseButtonStates are the ncurses button states of each button, in the
+// order of the actions press to triple click
+var mouseButtonStates = [][5]MouseButton{
+	{M_B1_PRESSED, M_B1_RELEASED, M_B1_CLICKED, M_B1_DBL_CLICKED,
+		M_B1_TPL_CLICKED},
+	{M_B2_PRESSED, M_B2_RELEASED, M_B2_CLICKED, M_B2_DBL_CLICKED,
+		M_B2_TPL_CLICKED},
+	{M_B3_PRESSED, M_B3_RELEASED, M_B3_CLICKED, M_B3_DBL_CLICKED,
+		M_B3_TPL_CLICKED},
+	{M_B4_PRESSED, M_B4_RELEASED, M_B4_CLICKED, M_B4_DBL_CLICKED,
+		M_B4_TPL_CLICKED},
+	{M_B5_PRESSED, M_B5_RELEASED, M_B5_CLICKED, M_B5_DBL_CLICKED,
+		M_B5_TPL_CLICKED},
+}
+
+// decode sets Button, Action and Modifiers from State. ncurses reports the
+// wheel as presses of buttons 4 (up) and 5 (down).
+func (me *MouseEvent) decode() {
+	if me.State&M_SHIFT != 0 {
+		me.Modifiers |= MOD_SHIFT
+	}
+	if me.State&M_ALT != 0 {
+		me.Modifiers |= MOD_ALT
+	}
+	if me.State&M_CTRL != 0 {
+		me.Modifiers |= MOD_CTRL
+	}
+	for i, states := range mouseButtonStates {
+		for j, state := range states {
+			if me.State&state != 0 {
+				me.Button, me.Action = i+1, MOUSE_PRESS+MouseAction(j)
+				if me.Button >= 4 && me.Action == MOUSE_PRESS {
+					me.Action = MOUSE_WHEEL
+				}
+				return
+			}
+		}
+	}
+	if me.State&M_POSITION != 0 {
+		me.Action = MOUSE_MOVE
+	}
+}
+
+// encode sets State from Button, Action and Modifiers
+func (me *MouseEvent) encode() {
+	switch {
+	case me.Action == MOUSE_DRAG || me.Action == MOUSE_MOVE:
+		me.State = M_POSITION
+	case me.Button >= 1 && me.Button <= len(mouseButtonStates):
+		action := me.Action
+		if action == MOUSE_WHEEL {
+			action = MOUSE_PRESS
+		}
+		me.State = mouseButtonStates[me.Button-1][action-MOUSE_PRESS]
+	}
+	if me.Modifiers&MOD_SHIFT != 0 {
+		me.State |= M_SHIFT
+	}
+	if me.Modifiers&MOD_ALT != 0 {
+		me.State |= M_ALT
+	}
+	if me.Modifiers&MOD_CTRL != 0 {
+		me.State |= M_CTRL
+	}
 }
 
 // GetMouse returns the MouseEvent associated with a KEY_MOUSE event returned
@@ -30,13 +120,15 @@ func GetMouse() *MouseEvent {
 	if C.ncurses_getmouse(&event) != C.OK {
 		return nil
 	}
-	return &MouseEvent{
+	me := &MouseEvent{
 		Id:    int16(event.id),
 		Y:     int(event.y),
 		X:     int(event.x),
 		Z:     int(event.z),
 		State: MouseButton(event.bstate),
 	}
+	me.decode()
+	return me
 }
 
 // MouseOk returns true if ncurses has built-in mouse support. On ncurses 5.7
@@ -62,3 +154,252 @@ func MouseMask(mask MouseButton, old *MouseButton) MouseButton {
 	return MouseButton(C.mousemask((C.mmask_t)(mask),
 		(*C.mmask_t)(unsafe.Pointer(old))))
 }
+
+// MouseTracking selects the mouse events reported by the terminal, see
+// SGRMouse
+type MouseTracking int
+
+const (
+	MOUSE_TRACK_OFF     MouseTracking = iota // no mouse events
+	MOUSE_TRACK_BUTTONS                      // button presses and releases
+	MOUSE_TRACK_DRAG                         // also motion with a button held
+	MOUSE_TRACK_MOTION                       // also any other motion
+)
+
+// keySGRMouse is bound to the start of an SGR mouse report. It follows the
+// key codes bound to the bracketed paste markers.
+const keySGRMouse Key = 0xfe02
+
+// SGRMouse turns on the terminal's SGR (1006) mouse reporting, or turns it
+// off if mode is MOUSE_TRACK_OFF. Reports are decoded by the library rather
+// than by ncurses and delivered as MouseEvents by EventLoop and Keymap.Read.
+// Unlike the reports enabled by MouseMask they carry no limit on the
+// coordinates and include the wheel, mouse motion and drags. Keypad must be
+// enabled on the window the input is read from and MouseMask should not be
+// used at the same time.
+//
+// Presses are reported as they happen. A release at the position the button
+// was pressed, within MouseInterval of it, is reported as a click instead;
+// successive clicks as double and triple clicks. SGR mouse reporting is
+// turned off by End.
+func SGRMouse(mode MouseTracking) error {
+	ti := CurrentTerminfo()
+	if ti == nil {
+		return errors.New("SGR mouse requires an initialized terminal")
+	}
+	seq := "\x1b[?1006l\x1b[?1003l\x1b[?1002l\x1b[?1000l"
+	var err error
+	switch mode {
+	case MOUSE_TRACK_OFF:
+		// hand the reports back to ncurses if the terminal uses them
+		if kmous, ok := ti.String("kmous"); ok && kmous == "\x1b[<" {
+			err = DefineKey(kmous, KEY_MOUSE)
+		} else {
+			err = DefineKey("\x1b[<", 0)
+		}
+	case MOUSE_TRACK_BUTTONS:
+		seq = "\x1b[?1000h\x1b[?1006h"
+	case MOUSE_TRACK_DRAG:
+		seq = "\x1b[?1000h\x1b[?1002h\x1b[?1006h"
+	case MOUSE_TRACK_MOTION:
+		seq = "\x1b[?1000h\x1b[?1003h\x1b[?1006h"
+	default:
+		return errors.New("Invalid mouse tracking mode")
+	}
+	if mode != MOUSE_TRACK_OFF {
+		err = DefineKey("\x1b[<", keySGRMouse)
+	}
+	if err != nil {
+		return err
+	}
+	if err := ti.Put(seq); err != nil {
+		return err
+	}
+	if mode != MOUSE_TRACK_OFF {
+		ti.setMode("mouse", func() { SGRMouse(MOUSE_TRACK_OFF) })
+	} else {
+		ti.setMode("mouse", nil)
+	}
+	return nil
+}
+
+// sgrMouse holds the state needed to resolve clicks from SGR reports
+var sgrMouse struct {
+	pressed     int // button held, if any
+	moved       bool
+	x, y        int
+	at          time.Time
+	clicks      int // successive clicks of clickButton
+	clickButton int
+	lastClick   time.Time
+}
+
+// readSGRMouse reads the rest of an SGR mouse report, such as "0;250;5M"
+// following "\x1b[<"
+func readSGRMouse(w *Window) *MouseEvent {
+	defer w.Timeout(w.Delay())
+	w.Timeout(pasteTimeout)
+	var b strings.Builder
+	for b.Len() < 32 {
+		r, k, err := w.GetWChar()
+		if err != nil || k != 0 {
+			return nil
+		}
+		if r == 'M' || r == 'm' {
+			return decodeSGRMouse(b.String(), r == 'm', time.Now())
+		}
+		b.WriteRune(r)
+	}
+	return nil
+}
+
+// decodeSGRMouse decodes the parameters of an SGR mouse report: the button
+// and modifiers, column and row. A report ending in 'm' is a release.
+func decodeSGRMouse(params string, release bool, now time.Time) *MouseEvent {
+	p := strings.Split(params, ";")
+	if len(p) != 3 {
+		return nil
+	}
+	var v [3]int
+	for i := range p {
+		n, err := strconv.Atoi(p[i])
+		if err != nil || n < 0 {
+			return nil
+		}
+		v[i] = n
+	}
+	b := v[0]
+	me := &MouseEvent{X: v[1] - 1, Y: v[2] - 1}
+	if b&4 != 0 {
+		me.Modifiers |= MOD_SHIFT
+	}
+	if b&8 != 0 {
+		me.Modifiers |= MOD_ALT
+	}
+	if b&16 != 0 {
+		me.Modifiers |= MOD_CTRL
+	}
+	switch {
+	case b&128 != 0:
+		me.Button = 8 + b&3
+	case b&64 != 0:
+		me.Button = 4 + b&3
+	case b&3 != 3:
+		me.Button = 1 + b&3
+	}
+
+	s := &sgrMouse
+	switch {
+	case b&32 != 0:
+		me.Action = MOUSE_DRAG
+		if me.Button == 0 {
+			me.Action = MOUSE_MOVE
+		}
+		if me.X != s.x || me.Y != s.y {
+			s.moved = true
+		}
+	case b&64 != 0:
+		me.Action = MOUSE_WHEEL
+	case !release:
+		me.Action = MOUSE_PRESS
+		s.pressed, s.moved = me.Button, false
+		s.x, s.y, s.at = me.X, me.Y, now
+	default:
+		me.Action = MOUSE_RELEASE
+		interval := time.Duration(MouseInterval(-1)) * time.Millisecond
+		if me.Button == s.pressed && !s.moved && me.X == s.x &&
+			me.Y == s.y && now.Sub(s.at) <= interval {
+			if s.clicks > 0 && s.clicks < 3 && s.clickButton == me.Button &&
+				now.Sub(s.lastClick) <= interval {
+				s.clicks++
+			} else {
+				s.clicks = 1
+			}
+			s.clickButton, s.lastClick = me.Button, now
+			me.Action = MOUSE_CLICK + MouseAction(s.clicks-1)
+		} else {
+			s.clicks = 0
+		}
+		s.pressed = 0
+	}
+	me.encode()
+	return me
+}
+
+// DragPhase is the stage of a drag reported by a DragEvent
+type DragPhase int
+
+const (
+	DRAG_START DragPhase = iota + 1 // mouse first moved with a button held
+	DRAG                            // mouse moved again
+	DRAG_END                        // button released
+)
+
+// DragEvent describes a drag, as reported by a DragTracker
+type DragEvent struct {
+	Phase          DragPhase
+	Button         int      // button held
+	Modifiers      Modifier // modifier keys held when the button was pressed
+	StartX, StartY int      // where the button was pressed
+	X, Y           int      // where the mouse is
+}
+
+// DragTracker follows the mouse events of a button being pressed, moved and
+// released and reports them as a drag. Motion is only reported by terminals
+// asked to track it, either by SGRMouse or by including M_POSITION in the
+// MouseMask, and in the latter case click resolution should be turned off
+// with MouseInterval(0) so that presses and releases are reported. The zero
+// value is ready to use.
+type DragTracker struct {
+	button         int
+	mod            Modifier
+	startX, startY int
+	x, y           int
+	dragging       bool
+}
+
+// Track feeds a mouse event to the tracker and returns the DragEvent it
+// results in, or nil if the event does not move or end a drag
+func (t *DragTracker) Track(me *MouseEvent) *DragEvent {
+	switch me.Action {
+	case MOUSE_PRESS:
+		*t = DragTracker{button: me.Button, mod: me.Modifiers,
+			startX: me.X, startY: me.Y, x: me.X, y: me.Y}
+	case MOUSE_DRAG, MOUSE_MOVE:
+		if t.button == 0 && me.Action == MOUSE_DRAG {
+			*t = DragTracker{button: me.Button, mod: me.Modifiers,
+				startX: me.X, startY: me.Y, x: me.X, y: me.Y}
+		}
+		if t.button == 0 || (me.X == t.x && me.Y == t.y) {
+			return nil
+		}
+		phase := DRAG
+		if !t.dragging {
+			phase, t.dragging = DRAG_START, true
+		}
+		t.x, t.y = me.X, me.Y
+		return t.event(phase)
+	case MOUSE_RELEASE, MOUSE_CLICK, MOUSE_DOUBLE_CLICK, MOUSE_TRIPLE_CLICK:
+		if t.button == 0 || (me.Button != 0 && me.Button != t.button) {
+			return nil
+		}
+		dragging := t.dragging
+		t.x, t.y = me.X, me.Y
+		ev := t.event(DRAG_END)
+		*t = DragTracker{}
+		if dragging {
+			return ev
+		}
+	}
+	return nil
+}
+
+// Dragging returns true while a drag is in progress
+func (t *DragTracker) Dragging() bool {
+	return t.dragging
+}
+
+func (t *DragTracker) event(phase DragPhase) *DragEvent {
+	return &DragEvent{Phase: phase, Button: t.button, Modifiers: t.mod,
+		StartX: t.startX, StartY: t.startY, X: t.x, Y: t.y}
+}
@@ -0,0 +1,73 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestSGRMouse(t *testing.T) {
+	scr := newScreen(t, 5, 300)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	if err := goncurses.SGRMouse(goncurses.MOUSE_TRACK_DRAG); err != nil {
+		t.Fatal(err)
+	}
+	goncurses.MouseInterval(1000)
+	loop := goncurses.NewEventLoop(w)
+	defer loop.Stop()
+
+	scr.Send("\x1b[<0;250;5M\x1b[<0;250;5m" + // click beyond column 223
+		"\x1b[<16;3;2M\x1b[<48;4;2M\x1b[<48;6;3M\x1b[<16;6;3m" + // ctrl drag
+		"\x1b[<65;1;1Mx")
+	expected := []goncurses.MouseEvent{
+		{X: 249, Y: 4, Button: 1, Action: goncurses.MOUSE_PRESS},
+		{X: 249, Y: 4, Button: 1, Action: goncurses.MOUSE_CLICK},
+		{X: 2, Y: 1, Button: 1, Action: goncurses.MOUSE_PRESS},
+		{X: 3, Y: 1, Button: 1, Action: goncurses.MOUSE_DRAG},
+		{X: 5, Y: 2, Button: 1, Action: goncurses.MOUSE_DRAG},
+		{X: 5, Y: 2, Button: 1, Action: goncurses.MOUSE_RELEASE},
+		{X: 0, Y: 0, Button: 5, Action: goncurses.MOUSE_WHEEL},
+	}
+	var drag goncurses.DragTracker
+	var drags []goncurses.DragPhase
+	for i, e := range expected {
+		ev, ok := (<-loop.Events()).(*goncurses.MouseEvent)
+		if !ok {
+			t.Fatalf("%d: expected a mouse event", i)
+		}
+		if ev.X != e.X || ev.Y != e.Y || ev.Button != e.Button ||
+			ev.Action != e.Action {
+			t.Errorf("%d: got %+v; want %+v", i, *ev, e)
+		}
+		if i >= 2 && i <= 5 && ev.Modifiers != goncurses.MOD_CTRL {
+			t.Errorf("%d: got modifiers %v; want MOD_CTRL", i, ev.Modifiers)
+		}
+		if d := drag.Track(ev); d != nil {
+			drags = append(drags, d.Phase)
+			if d.StartX != 2 || d.StartY != 1 || d.X != ev.X || d.Y != ev.Y {
+				t.Errorf("%d: got drag %+v", i, *d)
+			}
+		}
+	}
+	want := []goncurses.DragPhase{goncurses.DRAG_START, goncurses.DRAG,
+		goncurses.DRAG_END}
+	if len(drags) != len(want) {
+		t.Fatalf("got drag phases %v; want %v", drags, want)
+	}
+	for i := range want {
+		if drags[i] != want[i] {
+			t.Errorf("got drag phases %v; want %v", drags, want)
+		}
+	}
+	if ev := <-loop.Events(); ev != (goncurses.KeyEvent{Key: 'x', Rune: 'x'}) {
+		t.Errorf("got %#v; want key x", ev)
+	}
+}
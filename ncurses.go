@@ -5,17 +5,19 @@
 
 package goncurses
 
-// #cgo !darwin,!openbsd,!windows pkg-config: ncurses
-// #cgo windows CFLAGS: -DNCURSES_MOUSE_VERSION
+// #cgo !darwin,!openbsd,!windows pkg-config: ncursesw
+// #cgo windows CFLAGS: -DNCURSES_MOUSE_VERSION -DPDC_WIDE
 // #cgo windows LDFLAGS: -lpdcurses
+// #cgo darwin openbsd CFLAGS: -D_XOPEN_SOURCE_EXTENDED
 // #cgo darwin openbsd LDFLAGS: -lncurses
+// #include <stdlib.h>
 // #include <curses.h>
 // #include "goncurses.h"
 import "C"
 
 import (
 	"errors"
-	"fmt"
+	"unicode"
 	"unsafe"
 )
 
@@ -89,6 +91,48 @@ func Cursor(vis byte) error {
 	return nil
 }
 
+// DefineKey binds the escape sequence seq to the key code k, so that reading
+// the sequence from a window with Keypad enabled returns k. Any code may be
+// used, including ones not otherwise known to ncurses. If k is zero (0) the
+// binding for seq is removed and if seq is empty all bindings for k are
+// removed.
+func DefineKey(seq string, k Key) error {
+	var cseq *C.char
+	if seq != "" {
+		cseq = C.CString(seq)
+		defer C.free(unsafe.Pointer(cseq))
+	}
+	if C.ncurses_define_key(cseq, C.int(k)) == C.ERR {
+		return errors.New("Failed to define key")
+	}
+	if ti := CurrentTerminfo(); ti != nil {
+		delete(modifiedKeyTables, ti.term)
+	}
+	return nil
+}
+
+// ExtendedColorContent returns the RGB values for the specified color. It
+// behaves like ColorContent but accepts any color supported by the
+// terminal, including those beyond the range of an int16.
+func ExtendedColorContent(col int32) (int32, int32, int32, error) {
+	var r, g, b C.int
+	if C.ncurses_extended_color_content(C.int(col), &r, &g, &b) == C.ERR {
+		return -1, -1, -1, errors.New("Invalid color")
+	}
+	return int32(r), int32(g), int32(b), nil
+}
+
+// ExtendedPairContent returns the current foreground and background colours
+// associated with the given pair. It behaves like PairContent but accepts
+// any pair up to ColorPairs() and colors beyond the range of an int16.
+func ExtendedPairContent(pair int32) (fg int32, bg int32, err error) {
+	var f, b C.int
+	if C.ncurses_extended_pair_content(C.int(pair), &f, &b) == C.ERR {
+		return -1, -1, errors.New("Invalid color pair")
+	}
+	return int32(f), int32(b), nil
+}
+
 // Echo turns on/off the printing of typed characters
 func Echo(on bool) {
 	if on {
@@ -101,6 +145,7 @@ func Echo(on bool) {
 // Must be called prior to exiting the program in order to make sure the
 // terminal returns to normal operation
 func End() {
+	resetModes()
 	C.endwin()
 }
 
@@ -168,6 +213,34 @@ func InitColor(col, r, g, b int16) error {
 	return nil
 }
 
+// InitExtendedColor behaves like InitColor but accepts any color supported
+// by the terminal, as returned by Colors(). Values may be between 0 and
+// 1000. Requires ncurses 6.1 or later for colors beyond the range of int16.
+func InitExtendedColor(col, r, g, b int32) error {
+	if C.ncurses_init_extended_color(C.int(col), C.int(r), C.int(g),
+		C.int(b)) == C.ERR {
+		return errors.New("Failed to set new color definition")
+	}
+	return nil
+}
+
+// InitExtendedPair behaves like InitPair but accepts any pair up to
+// ColorPairs() and any color up to Colors(). On a direct color terminal,
+// such as xterm-direct, a color is its 24-bit RGB value. Because
+// ColorPair() encodes the pair in the attribute bits, which only have room
+// for 256 pairs, use Window.SetColorPair or WChar.Pair to select extended
+// pairs. Requires ncurses 6.1 or later for values beyond the range of int16.
+func InitExtendedPair(pair, fg, bg int32) error {
+	if pair <= 0 || C.int(pair) > C.int(C.COLOR_PAIRS-1) {
+		return errors.New("Color pair out of range")
+	}
+	if C.ncurses_init_extended_pair(C.int(pair), C.int(fg),
+		C.int(bg)) == C.ERR {
+		return errors.New("Failed to init color pair")
+	}
+	return nil
+}
+
 // InitPair sets a colour pair designated by 'pair' to fg and bg colors
 func InitPair(pair, fg, bg int16) error {
 	if pair <= 0 || C.int(pair) > C.int(C.COLOR_PAIRS-1) {
@@ -180,8 +253,11 @@ func InitPair(pair, fg, bg int16) error {
 }
 
 // Initialize the ncurses library. You must run this function prior to any
-// other goncurses function in order for the library to work
+// other goncurses function in order for the library to work. The program's
+// locale is set from the environment (LANG, LC_ALL, etc.) so that
+// multi-byte and wide characters are displayed correctly
 func Init() (stdscr *Window, err error) {
+	C.goncurses_setlocale()
 	stdscr = &Window{C.initscr()}
 	if unsafe.Pointer(stdscr.win) == nil {
 		err = errors.New("An error occurred initializing ncurses")
@@ -200,13 +276,58 @@ func IsTermResized(nlines, ncols int) bool {
 	return bool(C.is_term_resized(C.int(nlines), C.int(ncols)))
 }
 
-// Returns a string representing the value of input returned by GetChar
+// KeyBound returns the escape sequence bound to the key code k, or an empty
+// string if there is none
+func KeyBound(k Key) string {
+	s := C.ncurses_keybound(C.int(k), 0)
+	if s == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(s))
+	return C.GoString(s)
+}
+
+// KeyDefined returns the key code bound to the escape sequence seq. Zero (0)
+// is returned if the sequence is not bound and -1 if it is the prefix of a
+// longer sequence which is bound.
+func KeyDefined(seq string) Key {
+	cseq := C.CString(seq)
+	defer C.free(unsafe.Pointer(cseq))
+	return Key(C.ncurses_key_defined(cseq))
+}
+
+// KeyOk enables or disables recognition of the key code k. While disabled,
+// the escape sequences bound to k are passed through as ordinary characters.
+func KeyOk(k Key, enable bool) error {
+	if C.ncurses_keyok(C.int(k), C.bool(enable)) == C.ERR {
+		return errors.New("Failed to set key recognition")
+	}
+	return nil
+}
+
+// KeyString returns the name of a key returned by GetChar, such as "down",
+// "F5" or "C-x" (control-x). Printable characters are returned unchanged.
+// Other keys are named as by ncurses' keyname but without the "KEY_"
+// prefix, for example "DC" for the delete-character key. Keys pressed with
+// modifiers held are prefixed by "C-" (control), "M-" (alt) and "S-"
+// (shift), for example "C-left". ParseKey does the reverse; note that both
+// KEY_RETURN and KEY_ENTER are named "enter", which parses as KEY_RETURN.
 func KeyString(k Key) string {
-	key, ok := keyList[k]
-	if !ok {
-		key = fmt.Sprintf("%c", int(k))
+	if name, ok := keyList[k]; ok {
+		return name
+	}
+	if k >= 0 && k < ' ' || k == 127 {
+		return "C-" + string(unicode.ToLower(rune(k^0x40)))
+	}
+	if base, mod := DecodeKey(k); mod != 0 {
+		return modPrefix(mod) + KeyString(base)
+	}
+	if k >= C.KEY_MIN {
+		if name, ok := keyName(k); ok {
+			return name
+		}
 	}
-	return key
+	return string(rune(k))
 }
 
 // PairContent returns the current foreground and background colours
@@ -2,15 +2,68 @@
 package goncurses_test
 
 import (
+	"os"
 	"testing"
 
 	"github.com/rthornton128/goncurses"
 )
 
 func TestInit(t *testing.T) {
+	// Init writes to the real terminal and exits the process if the
+	// terminal type is unknown. Use the termtest package for headless tests.
+	if term := os.Getenv("TERM"); term == "" || term == "dumb" {
+		t.Skip("no terminal available")
+	}
 	_, err := goncurses.Init()
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer goncurses.End()
 }
+
+func TestExtendedPairs(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	if goncurses.ColorPairs() <= 300 {
+		t.Skip("terminal has too few color pairs")
+	}
+	if err := goncurses.InitExtendedPair(300, 196, 21); err != nil {
+		t.Fatal(err)
+	}
+	if err := goncurses.InitExtendedPair(301, 46, 226); err != nil {
+		t.Fatal(err)
+	}
+	if fg, bg, err := goncurses.ExtendedPairContent(300); fg != 196 ||
+		bg != 21 || err != nil {
+		t.Errorf("got %d, %d, %v; want 196, 21", fg, bg, err)
+	}
+	if goncurses.CanChangeColor() {
+		if err := goncurses.InitExtendedColor(200, 1000, 500, 0); err != nil {
+			t.Fatal(err)
+		}
+		if r, g, b, err := goncurses.ExtendedColorContent(200); r != 1000 ||
+			g != 500 || b != 0 || err != nil {
+			t.Errorf("got %d, %d, %d, %v; want 1000, 500, 0", r, g, b, err)
+		}
+	}
+
+	w := scr.Stdscr()
+	if err := w.SetColorPair(300); err != nil {
+		t.Fatal(err)
+	}
+	w.MovePrint(0, 0, "a")
+	w.SetColorPair(0)
+	w.MoveAddWChar(1, 0, goncurses.WChar{Rune: 'b', Pair: 301})
+	w.Refresh()
+
+	if c := scr.CellAt(0, 0); c.Rune != 'a' || c.Fg != 196 || c.Bg != 21 {
+		t.Errorf("got %+v; want 'a' in 196 on 21", c)
+	}
+	if c := scr.CellAt(1, 0); c.Rune != 'b' || c.Fg != 46 || c.Bg != 226 {
+		t.Errorf("got %+v; want 'b' in 46 on 226", c)
+	}
+}
@@ -32,6 +32,7 @@ func NewPad(h, w int) (*Pad, error) {
 // Pad.Refresh() for details on the arguments and Window.NoutRefresh for
 // more details on the workings of this function
 func (p *Pad) NoutRefresh(py, px, sy, sx, h, w int) error {
+	checkDispatch()
 	ok := C.pnoutrefresh(p.win, C.int(py), C.int(px), C.int(sy),
 		C.int(sx), C.int(h), C.int(w))
 	if ok != C.OK {
@@ -60,6 +61,7 @@ func (p *Pad) NoutRefresh(py, px, sy, sx, h, w int) error {
 // The coordinates of the rectangle must be contained within both the Pad's
 // and Window's respective areas.
 func (p *Pad) Refresh(py, px, sy1, sx1, sy2, sx2 int) error {
+	checkDispatch()
 	if C.prefresh(p.win, C.int(py), C.int(px), C.int(sy1), C.int(sx1),
 		C.int(sy2), C.int(sx2)) != C.OK {
 		return errors.New("Failed to refresh pad")
@@ -70,6 +72,7 @@ func (p *Pad) Refresh(py, px, sy1, sx1, sy2, sx2 int) error {
 // Sub creates a sub-pad h(eight) by w(idth) in size starting at the location
 // y, x in the parent pad. Changes to a sub-pad will also change it's parent
 func (p *Pad) Sub(y, x, h, w int) *Pad {
+	checkDispatch()
 	return &Pad{&Window{C.subpad(p.win, C.int(h), C.int(w), C.int(y),
 		C.int(x))}}
 }
@@ -14,5 +14,6 @@ import "C"
 // same effect of calling AddChar() + Refresh() but has a significant
 // speed advantage
 func (p *Pad) Echo(ch int) {
+	checkDispatch()
 	C.pechochar(p.win, C.chtype(ch))
 }
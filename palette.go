@@ -0,0 +1,179 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+// #include <curses.h>
+// #include "goncurses.h"
+import "C"
+
+import (
+	"container/list"
+	"errors"
+	"math"
+)
+
+// ErrNoColorPairs is returned by a Palette when every color pair the
+// terminal supports is already in use
+var ErrNoColorPairs = errors.New("All color pairs are in use")
+
+type colorKey struct{ fg, bg int32 }
+
+type paletteEntry struct {
+	key  colorKey
+	pair int32
+}
+
+// Palette allocates color pairs on demand, so that applications can work in
+// terms of foreground and background colors rather than managing pair
+// numbers by hand. Pairs are cached: asking for the same colors again
+// returns the same pair.
+//
+// With ncurses 6.1 and later the pairs are managed by ncurses' alloc_pair,
+// find_pair and free_pair functions, otherwise the palette keeps its own
+// least recently used cache and defines pairs with InitExtendedPair. Pairs
+// defined elsewhere with InitPair should not overlap those used by a
+// Palette when the fallback is in use.
+//
+// A Palette must be created after StartColor has been called.
+type Palette struct {
+	// Recycle allows the least recently used pair to be redefined once
+	// every pair is in use, instead of returning ErrNoColorPairs. Anything
+	// already drawn using the recycled pair will change color.
+	Recycle bool
+
+	native  bool
+	limit   int
+	entries map[colorKey]*list.Element
+	lru     *list.List
+	free    []int32
+	next    int32
+}
+
+// NewPalette returns a new, empty Palette able to allocate every color
+// pair supported by the terminal
+func NewPalette() *Palette {
+	p := &Palette{
+		native:  hasAllocPair(),
+		limit:   ColorPairs() - 1,
+		entries: make(map[colorKey]*list.Element),
+		lru:     list.New(),
+		next:    1,
+	}
+	if !p.native && p.limit > math.MaxInt16 {
+		p.limit = math.MaxInt16
+	}
+	return p
+}
+
+// hasAllocPair returns true if ncurses manages pairs with alloc_pair
+func hasAllocPair() bool {
+	return bool(C.ncurses_has_alloc_pair())
+}
+
+// Len returns the number of pairs currently allocated by the palette
+func (p *Palette) Len() int {
+	return p.lru.Len()
+}
+
+// Find returns the pair previously allocated by Pair for the given colors
+// and true, or false if no such pair exists. Unlike Pair, it never
+// allocates a new pair.
+func (p *Palette) Find(fg, bg int32) (int32, bool) {
+	if e, ok := p.entries[colorKey{fg, bg}]; ok {
+		return e.Value.(*paletteEntry).pair, true
+	}
+	if p.native {
+		if pair := C.ncurses_find_pair(C.int(fg), C.int(bg)); pair > 0 {
+			return int32(pair), true
+		}
+	}
+	return 0, false
+}
+
+// Pair returns a color pair with the given foreground and background colors,
+// allocating and initializing a new one if needed. Colors may be any value
+// accepted by InitExtendedPair, including -1 for the default colors if
+// UseDefaultColors has been called. ErrNoColorPairs is returned if all pairs
+// are in use and Recycle is false.
+func (p *Palette) Pair(fg, bg int32) (int32, error) {
+	key := colorKey{fg, bg}
+	if e, ok := p.entries[key]; ok {
+		p.lru.MoveToFront(e)
+		return e.Value.(*paletteEntry).pair, nil
+	}
+
+	if p.lru.Len() >= p.limit {
+		if !p.Recycle || p.lru.Len() == 0 {
+			return 0, ErrNoColorPairs
+		}
+		p.release(p.lru.Back())
+	}
+
+	var pair int32
+	if p.native {
+		if pair = int32(C.ncurses_alloc_pair(C.int(fg), C.int(bg))); pair < 0 {
+			return 0, errors.New("Failed to allocate color pair")
+		}
+	} else {
+		pair = p.next
+		if n := len(p.free); n > 0 {
+			pair, p.free = p.free[n-1], p.free[:n-1]
+		}
+		if err := InitExtendedPair(pair, fg, bg); err != nil {
+			p.free = append(p.free, pair)
+			return 0, err
+		}
+		if pair == p.next {
+			p.next++
+		}
+	}
+	p.entries[key] = p.lru.PushFront(&paletteEntry{key, pair})
+	return pair, nil
+}
+
+// Free releases the pair allocated for the given colors so that it may be
+// reused. It has no effect if no such pair has been allocated.
+func (p *Palette) Free(fg, bg int32) {
+	if e, ok := p.entries[colorKey{fg, bg}]; ok {
+		p.release(e)
+	}
+}
+
+func (p *Palette) release(e *list.Element) {
+	entry := p.lru.Remove(e).(*paletteEntry)
+	delete(p.entries, entry.key)
+	if p.native {
+		C.ncurses_free_pair(C.int(entry.pair))
+		return
+	}
+	p.free = append(p.free, entry.pair)
+}
+
+// Style is a combination of colors and attributes which can be applied to a
+// window with a Palette
+type Style struct {
+	Fg, Bg int32 // foreground and background colors
+	Attr   Char  // OR'd A_* attributes
+}
+
+// Apply sets the window's attributes and color pair to those of the style,
+// allocating a pair for its colors if necessary
+func (p *Palette) Apply(w *Window, s Style) error {
+	pair, err := p.Pair(s.Fg, s.Bg)
+	if err != nil {
+		return err
+	}
+	if err := w.AttrSet(s.Attr); err != nil {
+		return err
+	}
+	return w.SetColorPair(pair)
+}
+
+// WChar returns a wide character cell which displays r in the given style
+func (p *Palette) WChar(r rune, s Style) (WChar, error) {
+	pair, err := p.Pair(s.Fg, s.Bg)
+	return WChar{Rune: r, Attr: s.Attr, Pair: pair}, err
+}
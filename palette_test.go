@@ -0,0 +1,120 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestPalette(t *testing.T) {
+	for _, native := range []bool{true, false} {
+		name := "fallback"
+		if native {
+			name = "native"
+		}
+		t.Run(name, func(t *testing.T) { testPalette(t, native) })
+	}
+}
+
+func testPalette(t *testing.T, native bool) {
+	// xterm has 8 colors and 64 pairs, few enough to run out of
+	scr := newTermScreen(t, "xterm", 5, 20)
+	defer scr.End()
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	p := goncurses.NewPalette()
+	p.SetNative(native)
+	red, blue := int32(goncurses.C_RED), int32(goncurses.C_BLUE)
+
+	pair, err := p.Pair(red, blue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := p.Pair(red, blue); again != pair || p.Len() != 1 {
+		t.Errorf("got pair %d and %d pairs; want the cached pair %d", again,
+			p.Len(), pair)
+	}
+	if fg, bg, _ := goncurses.ExtendedPairContent(pair); fg != red ||
+		bg != blue {
+		t.Errorf("pair %d is %d on %d; want red on blue", pair, fg, bg)
+	}
+	if found, ok := p.Find(red, blue); !ok || found != pair {
+		t.Errorf("Find: got %d, %v; want %d", found, ok, pair)
+	}
+	if _, ok := p.Find(blue, red); ok {
+		t.Error("Find: found a pair which was never allocated")
+	}
+
+	w := scr.Stdscr()
+	style := goncurses.Style{Fg: int32(goncurses.C_GREEN),
+		Bg: int32(goncurses.C_BLACK), Attr: goncurses.A_BOLD}
+	if err := p.Apply(w, style); err != nil {
+		t.Fatal(err)
+	}
+	w.MovePrint(0, 0, "x")
+	w.Refresh()
+	if c := scr.CellAt(0, 0); c.Fg != int(goncurses.C_GREEN) ||
+		c.Bg != int(goncurses.C_BLACK) || c.Attr&goncurses.A_BOLD == 0 {
+		t.Errorf("got %+v; want bold green on black", c)
+	}
+
+	p.Free(red, blue)
+	if _, ok := p.Find(red, blue); ok || p.Len() != 1 {
+		t.Errorf("found a freed pair, or got %d pairs; want 1", p.Len())
+	}
+	reused, err := p.Pair(blue, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ncurses' alloc_pair prefers pairs never used to those freed
+	if !native && reused != pair {
+		t.Errorf("got pair %d; want freed pair %d reused", reused, pair)
+	}
+
+	// every combination of the 8 colors but red on red fills the 63 pairs
+	for fg := int32(0); fg < 8; fg++ {
+		for bg := int32(0); bg < 8; bg++ {
+			if fg == red && bg == red {
+				continue
+			}
+			if _, err := p.Pair(fg, bg); err != nil {
+				t.Fatalf("failed with %d pairs allocated: %v", p.Len(), err)
+			}
+		}
+	}
+	if p.Len() != goncurses.ColorPairs()-1 {
+		t.Fatalf("got %d pairs; want %d", p.Len(), goncurses.ColorPairs()-1)
+	}
+	if _, err := p.Pair(red, red); err != goncurses.ErrNoColorPairs {
+		t.Fatalf("got %v; want ErrNoColorPairs", err)
+	}
+
+	// black on black is the oldest pair, since the loop used the others
+	// again, but using it leaves black on red as the least recently used
+	black := int32(goncurses.C_BLACK)
+	p.Pair(black, black)
+	oldest, _ := p.Find(black, red)
+	p.Recycle = true
+	recycled, err := p.Pair(red, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Find(black, red); ok {
+		t.Error("expected the least recently used pair to be recycled")
+	}
+	if _, ok := p.Find(black, black); !ok {
+		t.Error("expected a recently used pair to be kept")
+	}
+	// as above, ncurses may choose a pair other than the one freed
+	if fg, bg, _ := goncurses.ExtendedPairContent(recycled); fg != red ||
+		bg != red || !native && recycled != oldest {
+		t.Errorf("got pair %d of %d on %d; want pair %d redefined", recycled,
+			fg, bg, oldest)
+	}
+}
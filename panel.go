@@ -4,7 +4,7 @@
 
 package goncurses
 
-// #cgo !darwin,!openbsd,!windows pkg-config: panel
+// #cgo !darwin,!openbsd,!windows pkg-config: panelw
 // #cgo darwin openbsd LDFLAGS: -lpanel
 // #include <panel.h>
 // #include <curses.h>
@@ -31,20 +31,44 @@ func UpdatePanels() {
 	return
 }
 
-// Returns a pointer to the panel above in the stack or nil. Passing nil will
-// return the top panel in the stack
+// Returns a pointer to the panel above in the stack or nil. Calling Above on
+// a nil panel returns the bottom panel in the stack
 func (p *Panel) Above() *Panel {
-	return &Panel{C.panel_above(p.pan)}
+	checkDispatch()
+	return newPanel(C.panel_above(p.cpanel()))
 }
 
-// Returns a pointer to the panel below in the stack or nil. Passing nil will
-// return the bottom panel in the stack
+// Returns a pointer to the panel below in the stack or nil. Calling Below on
+// a nil panel returns the top panel in the stack
+func (p *Panel) Below() *Panel {
+	checkDispatch()
+	return newPanel(C.panel_below(p.cpanel()))
+}
+
+// Below returns the panel below p in the stack.
+//
+// Deprecated: use the Below method, which this function calls.
 func Below(p *Panel) *Panel {
-	return &Panel{C.panel_above(p.pan)}
+	return p.Below()
+}
+
+func newPanel(pan *C.PANEL) *Panel {
+	if pan == nil {
+		return nil
+	}
+	return &Panel{pan}
+}
+
+func (p *Panel) cpanel() *C.PANEL {
+	if p == nil {
+		return nil
+	}
+	return p.pan
 }
 
 // Move the panel to the bottom of the stack.
 func (p *Panel) Bottom() error {
+	checkDispatch()
 	if C.bottom_panel(p.pan) == C.ERR {
 		return errors.New("Failed to move panel to bottom of stack")
 	}
@@ -53,6 +77,7 @@ func (p *Panel) Bottom() error {
 
 // Delete panel, removing from the stack.
 func (p *Panel) Delete() error {
+	checkDispatch()
 	if C.del_panel(p.pan) == C.ERR {
 		return errors.New("Failed to delete panel")
 	}
@@ -62,11 +87,13 @@ func (p *Panel) Delete() error {
 
 // Hidden returns true if panel is visible, false if not
 func (p *Panel) Hidden() bool {
+	checkDispatch()
 	return C.panel_hidden(p.pan) == C.TRUE
 }
 
 // Hide the panel
 func (p *Panel) Hide() error {
+	checkDispatch()
 	if C.hide_panel(p.pan) == C.ERR {
 		return errors.New("Failed to hide panel")
 	}
@@ -77,6 +104,7 @@ func (p *Panel) Hide() error {
 // ncurses movement functions on the window governed by panel. Always use
 // this function
 func (p *Panel) Move(y, x int) error {
+	checkDispatch()
 	if C.move_panel(p.pan, C.int(y), C.int(x)) == C.ERR {
 		return errors.New("Failed to move panel")
 	}
@@ -85,6 +113,7 @@ func (p *Panel) Move(y, x int) error {
 
 // Replace panel's associated window with a new one.
 func (p *Panel) Replace(w *Window) error {
+	checkDispatch()
 	if C.replace_panel(p.pan, w.win) == C.ERR {
 		return errors.New("Failed to replace window")
 	}
@@ -93,6 +122,7 @@ func (p *Panel) Replace(w *Window) error {
 
 // Show the panel, if hidden, and place it on the top of the stack.
 func (p *Panel) Show() error {
+	checkDispatch()
 	if C.show_panel(p.pan) == C.ERR {
 		return errors.New("Failed to show panel")
 	}
@@ -101,6 +131,7 @@ func (p *Panel) Show() error {
 
 // Move panel to the top of the stack
 func (p *Panel) Top() error {
+	checkDispatch()
 	if C.top_panel(p.pan) == C.ERR {
 		return errors.New("Failed to move panel to top of stack")
 	}
@@ -109,5 +140,6 @@ func (p *Panel) Top() error {
 
 // Window returns the window governed by panel
 func (p *Panel) Window() *Window {
+	checkDispatch()
 	return &Window{C.panel_window(p.pan)}
 }
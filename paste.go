@@ -0,0 +1,96 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"errors"
+	"strings"
+)
+
+// PasteEvent is delivered by an EventLoop, or returned by Keymap.Read, when
+// text is pasted into the terminal while bracketed paste mode is on. See
+// BracketedPaste.
+type PasteEvent struct {
+	Text string // pasted text, with line breaks converted to "\n"
+}
+
+// Key codes bound to the markers the terminal sends around pasted text. They
+// are well above those allocated by ncurses for the terminal's own keys.
+const (
+	keyPasteStart Key = 0xfe00
+	keyPasteEnd   Key = 0xfe01
+)
+
+// pasteTimeout is the longest time, in milliseconds, to wait for the rest of
+// a paste before delivering what has been read
+const pasteTimeout = 100
+
+// BracketedPaste turns bracketed paste mode on or off for the current
+// terminal. While on, the terminal marks text pasted into it and the text is
+// delivered as a single PasteEvent by EventLoop and Keymap.Read, rather than
+// as keys which would trigger shortcuts or field autoskip. Keypad must be
+// enabled on the window the input is read from. Pass the text to Form.Paste
+// to enter it into a form without skipping between fields.
+//
+// Only EventLoop and Keymap.Read understand the markers. GetChar and
+// GetWChar return each as a key code above KEY_MAX, before and after the
+// pasted characters, while GetString and GetWString ignore them and take
+// the text as though it were typed, so that a line break ends the input.
+//
+// The sequences used are taken from the terminal's BE, BD, PS and PE
+// capabilities or, if they are missing, those of xterm. Bracketed paste is
+// turned off by End.
+func BracketedPaste(on bool) error {
+	ti := CurrentTerminfo()
+	if ti == nil {
+		return errors.New("Bracketed paste requires an initialized terminal")
+	}
+	capability := func(name, def string) string {
+		if s, ok := ti.String(name); ok {
+			return s
+		}
+		return def
+	}
+	seq := capability("BD", "\x1b[?2004l")
+	if on {
+		seq = capability("BE", "\x1b[?2004h")
+		err := DefineKey(capability("PS", "\x1b[200~"), keyPasteStart)
+		if err != nil {
+			return err
+		}
+		err = DefineKey(capability("PE", "\x1b[201~"), keyPasteEnd)
+		if err != nil {
+			return err
+		}
+	}
+	if err := ti.Put(seq); err != nil {
+		return err
+	}
+	if on {
+		ti.setMode("paste", func() { BracketedPaste(false) })
+	} else {
+		ti.setMode("paste", nil)
+	}
+	return nil
+}
+
+// readPaste reads pasted text up to the end marker
+func readPaste(w *Window) Event {
+	defer w.Timeout(w.Delay())
+	w.Timeout(pasteTimeout)
+	var b strings.Builder
+	for {
+		r, k, err := w.GetWChar()
+		if err != nil || k == keyPasteEnd {
+			break
+		}
+		if k == 0 {
+			b.WriteRune(r)
+		}
+	}
+	text := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(b.String())
+	return PasteEvent{Text: text}
+}
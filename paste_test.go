@@ -0,0 +1,108 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestBracketedPaste(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	if err := goncurses.BracketedPaste(true); err != nil {
+		t.Fatal(err)
+	}
+	loop := goncurses.NewEventLoop(w)
+	defer loop.Stop()
+
+	scr.Send("\x1b[200~hello\rworld\x1b[201~x")
+	expected := []goncurses.Event{
+		goncurses.PasteEvent{Text: "hello\nworld"},
+		goncurses.KeyEvent{Key: 'x', Rune: 'x'},
+	}
+	for _, e := range expected {
+		if ev := <-loop.Events(); ev != e {
+			t.Errorf("got %#v; want %#v", ev, e)
+		}
+	}
+}
+
+func TestFormPaste(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	first, _ := goncurses.NewField(1, 5, 0, 0, 0, 0)
+	defer first.Free()
+	second, _ := goncurses.NewField(1, 5, 1, 0, 0, 0)
+	defer second.Free()
+	form, _ := goncurses.NewForm([]*goncurses.Field{first, second})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+
+	if err := form.Paste("a b\ncdefgh"); err == nil {
+		t.Error("expected error pasting more than the field holds")
+	}
+	form.Driver(goncurses.REQ_VALIDATION)
+	if got := first.Buffer(); got != "a b c" {
+		t.Errorf("got %q, want %q", got, "a b c")
+	}
+	if got := second.Buffer(); got != "     " {
+		t.Errorf("second field changed: %q", got)
+	}
+}
+
+func TestFormPasteEvent(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	first, _ := goncurses.NewField(1, 5, 0, 0, 0, 0)
+	defer first.Free()
+	second, _ := goncurses.NewField(1, 5, 1, 0, 0, 0)
+	defer second.Free()
+	form, _ := goncurses.NewForm([]*goncurses.Field{first, second})
+	defer form.Free()
+	form.Post()
+	defer form.UnPost()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	if err := goncurses.BracketedPaste(true); err != nil {
+		t.Fatal(err)
+	}
+	keys := goncurses.NewFormKeymap(nil)
+	loop := goncurses.NewEventLoop(w)
+	defer loop.Stop()
+
+	// typed, the last character would fill the first field and autoskip to
+	// the second, which would then receive the x
+	scr.Send("\x1b[200~abcde\x1b[201~x")
+	for i := 0; i < 2; i++ {
+		switch ev := (<-loop.Events()).(type) {
+		case goncurses.PasteEvent:
+			goncurses.Do(func() { form.Paste(ev.Text) })
+		case goncurses.KeyEvent:
+			goncurses.Do(func() { form.Drive(keys, ev) })
+		default:
+			t.Fatalf("got %#v; want a paste and a key", ev)
+		}
+	}
+	goncurses.Do(func() {
+		current := form.Current()
+		form.Driver(goncurses.REQ_VALIDATION)
+		if current != first || first.Buffer() != "abcde" ||
+			second.Buffer() != "     " {
+			t.Errorf("got %q and %q with the first field current %v; want "+
+				"the paste to stay in the first field", first.Buffer(),
+				second.Buffer(), current == first)
+		}
+	})
+}
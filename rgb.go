@@ -0,0 +1,239 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RGB is a 24-bit color made up of red, green and blue components
+type RGB struct {
+	R, G, B uint8
+}
+
+// ParseRGB parses a color written in hexadecimal notation, either as
+// "#rrggbb" or the short form "#rgb". The leading '#' is optional.
+func ParseRGB(s string) (RGB, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return RGB{}, errors.New("Invalid RGB color: " + s)
+	}
+	return RGB{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// String returns the color in "#rrggbb" notation
+func (c RGB) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// The standard xterm palette. The first 16 entries are the ANSI colors,
+// followed by either the 88 or 256 color extensions.
+var (
+	ansiPalette = []RGB{
+		{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+		{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+		{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+		{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+	}
+	palette88 = xtermPalette([]uint8{0, 139, 205, 255},
+		[]uint8{46, 92, 115, 139, 162, 185, 208, 231})
+	palette256 = xtermPalette([]uint8{0, 95, 135, 175, 215, 255},
+		grayRamp(24, 8, 10))
+)
+
+// xtermPalette builds an xterm palette: the ANSI colors followed by a color
+// cube with the given component levels and then a ramp of grays
+func xtermPalette(levels, grays []uint8) []RGB {
+	p := append([]RGB{}, ansiPalette...)
+	for _, r := range levels {
+		for _, g := range levels {
+			for _, b := range levels {
+				p = append(p, RGB{r, g, b})
+			}
+		}
+	}
+	for _, v := range grays {
+		p = append(p, RGB{v, v, v})
+	}
+	return p
+}
+
+func grayRamp(n int, start, step uint8) []uint8 {
+	grays := make([]uint8, n)
+	for i := range grays {
+		grays[i] = start + uint8(i)*step
+	}
+	return grays
+}
+
+// standardPalette returns the default palette of a terminal supporting the
+// given number of colors
+func standardPalette(colors int) []RGB {
+	switch {
+	case colors >= 256:
+		return palette256
+	case colors >= 88:
+		return palette88
+	case colors >= 16:
+		return ansiPalette
+	}
+	return ansiPalette[:8]
+}
+
+// lab is a color in the CIE L*a*b* color space, in which euclidean distance
+// approximates the perceived difference between colors
+type lab struct{ l, a, b float64 }
+
+func (c RGB) lab() lab {
+	linear := func(v uint8) float64 {
+		c := float64(v) / 255
+		if c <= 0.04045 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return lab{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+func (c lab) distance(o lab) float64 {
+	dl, da, db := c.l-o.l, c.a-o.a, c.b-o.b
+	return math.Sqrt(dl*dl + da*da + db*db)
+}
+
+// nearest returns the index of the entry in the palette closest to c and
+// the perceptual distance between them
+func nearest(c lab, palette []lab) (int, float64) {
+	best, dist := 0, math.Inf(1)
+	for i, p := range palette {
+		if d := c.distance(p); d < dist {
+			best, dist = i, d
+		}
+	}
+	return best, dist
+}
+
+// directColor returns the color number used for c on a direct color
+// terminal. Numbers below 8 select the ANSI colors on such terminals so
+// the darkest blues are displayed as black.
+func directColor(c RGB) int32 {
+	v := int32(c.R)<<16 | int32(c.G)<<8 | int32(c.B)
+	if v < 8 {
+		return int32(C_BLACK)
+	}
+	return v
+}
+
+// NearestColor returns the color number which best matches c on a terminal
+// supporting the given number of colors, such as returned by Colors(). The
+// standard xterm palettes are assumed for 8, 16, 88 and 256 color
+// terminals. On direct color terminals (16777216 colors) the color's RGB
+// value is returned.
+func NearestColor(c RGB, colors int) int32 {
+	if colors >= 1<<24 {
+		return directColor(c)
+	}
+	p := standardPalette(colors)
+	labs := make([]lab, len(p))
+	for i, pc := range p {
+		labs[i] = pc.lab()
+	}
+	i, _ := nearest(c.lab(), labs)
+	return int32(i)
+}
+
+// ColorMatchThreshold is the perceptual distance within which a ColorMatcher
+// considers an existing palette entry close enough to use rather than
+// redefining a new color. A distance of about 2.3 is the smallest
+// difference most people can notice.
+var ColorMatchThreshold = 2.3
+
+// ColorMatcher maps RGB colors to the color numbers of the current terminal.
+// On terminals which can redefine their colors (see CanChangeColor) new
+// colors are defined with InitExtendedColor as they are needed, leaving the
+// 16 ANSI colors and any color already returned by Match untouched;
+// previously defined colors are reused for the same or similar values.
+// Otherwise, or once every color has been redefined, the nearest color in the
+// terminal's palette is used.
+//
+// A ColorMatcher must be created after StartColor has been called.
+type ColorMatcher struct {
+	colors  int
+	direct  bool
+	palette []lab
+	next    int
+	used    map[int]bool
+	cache   map[RGB]int32
+}
+
+// NewColorMatcher returns a ColorMatcher for the current terminal
+func NewColorMatcher() *ColorMatcher {
+	m := &ColorMatcher{
+		colors: Colors(),
+		direct: Colors() >= 1<<24,
+		used:   make(map[int]bool),
+		cache:  make(map[RGB]int32),
+	}
+	if m.direct {
+		return m
+	}
+	for _, c := range standardPalette(m.colors) {
+		m.palette = append(m.palette, c.lab())
+	}
+	m.next = len(ansiPalette)
+	if !CanChangeColor() {
+		m.next = m.colors
+	}
+	return m
+}
+
+// Match returns the color number to use for c. The result may be passed as
+// a foreground or background color to InitExtendedPair or Palette.Pair.
+func (m *ColorMatcher) Match(c RGB) int32 {
+	if m.direct {
+		return directColor(c)
+	}
+	if col, ok := m.cache[c]; ok {
+		return col
+	}
+	l := c.lab()
+	i, dist := nearest(l, m.palette)
+	if dist > ColorMatchThreshold {
+		for m.next < m.colors && m.used[m.next] {
+			m.next++
+		}
+		if m.next < m.colors && InitExtendedColor(int32(m.next),
+			int32(c.R)*1000/255, int32(c.G)*1000/255,
+			int32(c.B)*1000/255) == nil {
+			i = m.next
+			for len(m.palette) <= i {
+				m.palette = append(m.palette, lab{math.Inf(1), 0, 0})
+			}
+			m.palette[i] = l
+		}
+	}
+	m.used[i] = true
+	m.cache[c] = int32(i)
+	return int32(i)
+}
@@ -0,0 +1,119 @@
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestNearestColor(t *testing.T) {
+	tests := []struct {
+		hex      string
+		colors   int
+		expected int32
+	}{
+		{"#000000", 8, 0},
+		{"#ff0000", 8, 1},
+		{"#ee1111", 16, 9},
+		{"#0000ff", 16, 4},
+		{"#ff8700", 256, 208},
+		{"#808080", 256, 244},
+		{"#ffffff", 256, 15},
+		{"#8b8b00", 88, 36},
+		{"#123456", 1 << 24, 0x123456},
+		{"#000005", 1 << 24, 0},
+	}
+	for _, test := range tests {
+		c, err := goncurses.ParseRGB(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := goncurses.NearestColor(c, test.colors); got != test.expected {
+			t.Errorf("%s with %d colors: got %d; want %d", test.hex,
+				test.colors, got, test.expected)
+		}
+	}
+}
+
+func TestParseRGB(t *testing.T) {
+	if c, err := goncurses.ParseRGB("#f80"); err != nil ||
+		c != (goncurses.RGB{0xff, 0x88, 0x00}) {
+		t.Errorf("got %v, %v", c, err)
+	}
+	if _, err := goncurses.ParseRGB("#12345"); err == nil {
+		t.Error("expected error for invalid color")
+	}
+	if s := (goncurses.RGB{1, 2, 255}).String(); s != "#0102ff" {
+		t.Errorf("got %q", s)
+	}
+}
+
+func TestColorMatcherFixed(t *testing.T) {
+	// xterm has 8 colors and cannot redefine them
+	scr := newTermScreen(t, "xterm", 5, 20)
+	defer scr.End()
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	m := goncurses.NewColorMatcher()
+	for _, c := range []goncurses.RGB{{255, 0, 0}, {0x12, 0x34, 0x56}} {
+		if got, want := m.Match(c), goncurses.NearestColor(c, 8); got != want {
+			t.Errorf("%v: got %d; want nearest color %d", c, got, want)
+		}
+	}
+}
+
+func TestColorMatcher(t *testing.T) {
+	scr := newTermScreen(t, "xterm-256color", 5, 20)
+	defer scr.End()
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	if !goncurses.CanChangeColor() {
+		t.Skip("terminal cannot change colors")
+	}
+	m := goncurses.NewColorMatcher()
+
+	// colors of the palette, or within the threshold of one, are reused
+	if got := m.Match(goncurses.RGB{205, 0, 0}); got != 1 {
+		t.Errorf("got %d; want ANSI red", got)
+	}
+	if got := m.Match(goncurses.RGB{206, 1, 0}); got != 1 {
+		t.Errorf("got %d; want ANSI red reused", got)
+	}
+
+	// others redefine the first color after the ANSI colors
+	c := m.Match(goncurses.RGB{0x12, 0x34, 0x57})
+	if c != 16 {
+		t.Fatalf("got %d; want 16 redefined", c)
+	}
+	if r, g, b, _ := goncurses.ExtendedColorContent(c); r != 70 || g != 203 ||
+		b != 341 {
+		t.Errorf("color %d is %d, %d, %d; want 70, 203, 341", c, r, g, b)
+	}
+	if got := m.Match(goncurses.RGB{0x12, 0x34, 0x56}); got != c {
+		t.Errorf("got %d; want redefined color %d reused", got, c)
+	}
+
+	// with no threshold every new value needs a color of its own, until
+	// there are none left to redefine
+	defer func(threshold float64) {
+		goncurses.ColorMatchThreshold = threshold
+	}(goncurses.ColorMatchThreshold)
+	goncurses.ColorMatchThreshold = -1
+	seen := map[int32]bool{c: true}
+	var last int32
+	for i := 0; i < 256-17; i++ {
+		last = m.Match(goncurses.RGB{uint8(i), 0, 200})
+		if last < 17 || last > 255 || seen[last] {
+			t.Fatalf("got %d; want a color not yet used", last)
+		}
+		seen[last] = true
+	}
+	if got := m.Match(goncurses.RGB{240, 0, 200}); got != last {
+		t.Errorf("got %d; want the nearest color, %d", got, last)
+	}
+	if _, _, b, _ := goncurses.ExtendedColorContent(last); b != 784 {
+		t.Errorf("got blue of %d; want color %d left unchanged", b, last)
+	}
+}
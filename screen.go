@@ -10,6 +10,7 @@ package goncurses
 // #endif
 // #include <stdlib.h>
 // #include <curses.h>
+// #include "goncurses.h"
 import "C"
 
 import (
@@ -41,11 +42,13 @@ func NewTerm(termType string, out, in *os.File) (*Screen, error) {
 	defer C.free(unsafe.Pointer(wr))
 	defer C.free(unsafe.Pointer(rd))
 
+	C.goncurses_setlocale()
 	cout, cin := C.fdopen(C.int(out.Fd()), wr), C.fdopen(C.int(in.Fd()), rd)
 	screen := C.newterm(tt, cout, cin)
 	if screen == nil {
 		return nil, errors.New("Failed to create new screen")
 	}
+	setTerminalOutput(out)
 	return &Screen{screen}, nil
 }
 
@@ -60,6 +63,7 @@ func (s *Screen) Set() (*Screen, error) {
 
 // Delete frees memory allocated to the screen. This function
 func (s *Screen) Delete() {
+	forgetTerminal(s.scrPtr)
 	C.delscreen(s.scrPtr)
 }
 
@@ -0,0 +1,31 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rthornton128/goncurses/termtest"
+)
+
+// newScreen starts ncurses on a virtual terminal of rows by cols, skipping
+// the test on systems without pseudo-terminal support
+func newScreen(t *testing.T, rows, cols int) *termtest.Screen {
+	return newTermScreen(t, termtest.DefaultTerm, rows, cols)
+}
+
+// newTermScreen is newScreen for the given terminal type
+func newTermScreen(t *testing.T, term string, rows, cols int) *termtest.Screen {
+	scr, err := termtest.New(term, rows, cols)
+	if errors.Is(err, termtest.ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scr
+}
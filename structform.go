@@ -0,0 +1,466 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// #include <form.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StructForm is a form for editing the fields of a struct, created by
+// FormFor
+type StructForm struct {
+	form    Form
+	fields  []*Field // all fields of the form, labels included
+	entries []*formEntry
+	value   reflect.Value
+}
+
+// formEntry is a struct field edited by a StructForm
+type formEntry struct {
+	name     string // name of the struct field
+	index    int
+	label    string
+	field    *Field
+	kind     string // value of the type option
+	min, max string
+	width    int
+	decimals int
+	values   []string
+	pattern  *regexp.Regexp
+	typ      *FieldType // type created for the field, if any
+	required bool
+}
+
+// FieldError is the error returned by StructForm.Submit for a field whose
+// contents could not be stored in the struct
+type FieldError struct {
+	Name  string // name of the struct field
+	Label string // label shown on the form
+	Field *Field
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Label + ": " + e.Err.Error()
+}
+
+// FormErrors is the list of FieldErrors returned by StructForm.Submit
+type FormErrors []*FieldError
+
+func (e FormErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// FormFor returns a form for editing the struct pointed to by v. Each
+// exported field of the struct of type string, bool, integer or floating
+// point is shown on its own line with a label, and is filled in with the
+// field's value. The form is configured with the "form" tag of each field,
+// a comma separated list of options such as:
+//
+//	Port int `form:"label=Port,width=5,type=int,min=1,max=65535"`
+//
+// The options are:
+//
+//	label=text     label shown, by default the field's name
+//	width=n        width of the field
+//	type=name      one of int, float, alpha, alnum, enum, ipv4 or regexp;
+//	               by default int, float or text according to the field
+//	min=n, max=n   range of an int or float
+//	precision=n    digits shown after the decimal point of a float
+//	values=a|b|c   the values of an enum
+//	required       the field may not be left blank
+//	regexp=re      the expression, in the syntax of package regexp, which
+//	               the whole of a regexp field must match; it may contain
+//	               commas and so must come last
+//
+// A tag of "-" omits the field. Bool fields are enums of "yes" and "no".
+// The contents of the form are stored back in the struct by Submit.
+func FormFor(v interface{}) (*StructForm, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("FormFor requires a pointer to a struct")
+	}
+	sf := &StructForm{value: rv.Elem()}
+	rt := sf.value.Type()
+	labelWidth := 0
+	for i := 0; i < rt.NumField(); i++ {
+		e, err := newFormEntry(rt.Field(i), i)
+		if err != nil {
+			sf.free()
+			return nil, err
+		}
+		if e == nil {
+			continue
+		}
+		sf.entries = append(sf.entries, e)
+		if len(e.label) > labelWidth {
+			labelWidth = len(e.label)
+		}
+	}
+	if len(sf.entries) == 0 {
+		return nil, errors.New("No fields to edit in " + rt.String())
+	}
+
+	for y, e := range sf.entries {
+		label, err := NewField(1, int32(len(e.label)+1), int32(y), 0, 0, 0)
+		if err != nil {
+			sf.free()
+			return nil, err
+		}
+		label.SetBuffer(e.label + ":")
+		label.Options(FO_ACTIVE, false)
+		sf.fields = append(sf.fields, label)
+
+		width := e.fieldWidth(sf.value.Field(e.index).Kind())
+		field, err := NewField(1, int32(width), int32(y),
+			int32(labelWidth+2), 0, 0)
+		if err != nil {
+			sf.free()
+			return nil, err
+		}
+		sf.fields = append(sf.fields, field)
+		e.field = field
+		if err := e.setup(sf.value.Field(e.index)); err != nil {
+			sf.free()
+			return nil, fmt.Errorf("%s: %v", e.name, err)
+		}
+	}
+	// the form keeps the list of fields, which must stay in memory
+	sf.fields = append(sf.fields, nil)
+	sf.form, _ = NewForm(sf.fields)
+	if sf.form.form == nil {
+		sf.free()
+		return nil, errors.New("Failed to create form")
+	}
+	return sf, nil
+}
+
+// newFormEntry parses the tag of a struct field, returning nil if the field
+// is not to be edited
+func newFormEntry(sf reflect.StructField, index int) (*formEntry, error) {
+	tag := sf.Tag.Get("form")
+	if tag == "-" || sf.PkgPath != "" {
+		return nil, nil
+	}
+	e := &formEntry{name: sf.Name, index: index, label: sf.Name}
+	switch sf.Type.Kind() {
+	case reflect.String:
+		e.kind = "text"
+	case reflect.Bool:
+		e.kind, e.values = "enum", []string{"yes", "no"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		e.kind = "int"
+	case reflect.Float32, reflect.Float64:
+		e.kind = "float"
+	default:
+		if tag == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: can not edit a %s", sf.Name, sf.Type)
+	}
+	for rest := tag; rest != ""; {
+		opt := rest
+		if strings.HasPrefix(rest, "regexp=") {
+			rest = ""
+		} else if i := strings.Index(rest, ","); i >= 0 {
+			opt, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+		key, value := opt, ""
+		if i := strings.Index(opt, "="); i >= 0 {
+			key, value = opt[:i], opt[i+1:]
+		}
+		switch key {
+		case "label":
+			e.label = value
+		case "width", "precision":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s: invalid %s %q", sf.Name, key,
+					value)
+			}
+			if key == "width" {
+				e.width = n
+			} else {
+				e.decimals = n
+			}
+		case "type":
+			e.kind = value
+		case "min":
+			e.min = value
+		case "max":
+			e.max = value
+		case "values":
+			e.values = strings.Split(value, "|")
+		case "required":
+			e.required = true
+		case "regexp":
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", sf.Name, err)
+			}
+			e.kind, e.pattern = "regexp", re
+		default:
+			return nil, fmt.Errorf("%s: unknown form option %q", sf.Name,
+				key)
+		}
+	}
+	return e, nil
+}
+
+// fieldWidth returns the width of a field, by default according to its type
+func (e *formEntry) fieldWidth(kind reflect.Kind) int {
+	switch {
+	case e.width > 0:
+		return e.width
+	case kind == reflect.Bool:
+		return 3
+	case e.kind == "int":
+		return 11
+	case e.kind == "float":
+		return 16
+	case e.kind == "ipv4":
+		return 15
+	}
+	return 20
+}
+
+// setup sets the field's type and options and shows the value v
+func (e *formEntry) setup(v reflect.Value) error {
+	var typ *FieldType
+	switch e.kind {
+	case "text":
+	case "int":
+		// ncurses only checks the range when both ends are given
+		min, errMin := strconv.ParseInt(e.min, 10, 64)
+		max, errMax := strconv.ParseInt(e.max, 10, 64)
+		if errMin != nil || errMax != nil {
+			min, max = 0, 0
+		}
+		typ = IntegerType(0, int(min), int(max))
+	case "float":
+		min, errMin := strconv.ParseFloat(e.min, 64)
+		max, errMax := strconv.ParseFloat(e.max, 64)
+		if errMin != nil || errMax != nil {
+			min, max = 0, 0
+		}
+		typ = NumericType(e.decimals, min, max)
+	case "alpha":
+		typ = AlphaType(0)
+	case "alnum":
+		typ = AlnumType(0)
+	case "enum":
+		if len(e.values) == 0 {
+			return errors.New("enum without values")
+		}
+		typ = EnumType(e.values, false, true)
+	case "ipv4":
+		typ = IPv4Type()
+	case "regexp":
+		if e.pattern == nil {
+			return errors.New("regexp without an expression")
+		}
+		// the expression is checked in Go, as ncurses' TYPE_REGEXP would
+		// take it to be a POSIX one
+		re := e.pattern
+		var err error
+		typ, err = NewFieldType(func(f *Field) bool {
+			return re.MatchString(strings.TrimSpace(f.Buffer()))
+		}, nil)
+		if err != nil {
+			return err
+		}
+		e.typ = typ
+	default:
+		return errors.New("unknown field type " + e.kind)
+	}
+	if typ != nil {
+		if err := e.field.SetType(typ); err != nil {
+			return err
+		}
+	}
+	if e.required {
+		e.field.Options(FO_NULLOK, false)
+	}
+	// validate fields even when left unchanged
+	e.field.Options(FO_PASSOK, false)
+
+	s := fmt.Sprint(v.Interface())
+	switch v.Kind() {
+	case reflect.Bool:
+		s = "no"
+		if v.Bool() {
+			s = "yes"
+		}
+	case reflect.Float32, reflect.Float64:
+		if e.decimals > 0 {
+			s = strconv.FormatFloat(v.Float(), 'f', e.decimals, 64)
+		}
+	}
+	return e.field.SetBuffer(s)
+}
+
+// Form returns the form, which may be posted and driven in the usual way
+func (sf *StructForm) Form() *Form {
+	return &sf.form
+}
+
+// Submit stores the contents of the form in the struct. If any field can
+// not be stored, because it is blank when required or is not a valid value
+// of its type or of the struct field, a FormErrors listing each such field
+// is returned and the struct is left unchanged.
+func (sf *StructForm) Submit() error {
+	checkDispatch()
+	// the contents of the current field are only stored once validated
+	current := (*Field)(C.current_field(sf.form.form))
+	validErr := sf.form.Driver(REQ_VALIDATION)
+
+	var errs FormErrors
+	values := make([]reflect.Value, len(sf.entries))
+	for i, e := range sf.entries {
+		err := validErr
+		if e.field != current || validErr == nil {
+			values[i], err = e.parse(sf.value.Field(e.index).Type())
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Name: e.name, Label: e.label,
+				Field: e.field, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for i, e := range sf.entries {
+		sf.value.Field(e.index).Set(values[i])
+	}
+	return nil
+}
+
+// parse returns the value of the field converted to type t
+func (e *formEntry) parse(t reflect.Type) (reflect.Value, error) {
+	s := strings.TrimSpace(e.field.Buffer())
+	v := reflect.New(t).Elem()
+	if s == "" {
+		if e.required {
+			return v, errors.New("required")
+		}
+		return v, nil
+	}
+	switch e.kind {
+	case "enum":
+		found := false
+		for _, value := range e.values {
+			found = found || strings.EqualFold(value, s)
+		}
+		if !found {
+			return v, fmt.Errorf("must be one of %s",
+				strings.Join(e.values, ", "))
+		}
+	case "regexp":
+		if !e.pattern.MatchString(s) {
+			return v, errors.New("invalid format")
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(strings.EqualFold(s, "yes"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err == nil {
+			err = e.checkRange(float64(n))
+		}
+		if err != nil {
+			return v, cleanNumError(err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err == nil {
+			err = e.checkRange(float64(n))
+		}
+		if err != nil {
+			return v, cleanNumError(err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, t.Bits())
+		if err == nil {
+			err = e.checkRange(n)
+		}
+		if err != nil {
+			return v, cleanNumError(err)
+		}
+		v.SetFloat(n)
+	}
+	return v, nil
+}
+
+// checkRange checks that n is between the field's min and max, if set
+func (e *formEntry) checkRange(n float64) error {
+	min, errMin := strconv.ParseFloat(e.min, 64)
+	max, errMax := strconv.ParseFloat(e.max, 64)
+	switch {
+	case errMin == nil && errMax == nil && (n < min || n > max):
+		return fmt.Errorf("must be between %s and %s", e.min, e.max)
+	case errMin == nil && n < min:
+		return fmt.Errorf("must be at least %s", e.min)
+	case errMax == nil && n > max:
+		return fmt.Errorf("must be at most %s", e.max)
+	}
+	return nil
+}
+
+// cleanNumError removes the function name and input from errors returned
+// by strconv
+func cleanNumError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
+
+// Free frees the form and its fields
+func (sf *StructForm) Free() error {
+	checkDispatch()
+	err := sf.form.Free()
+	sf.free()
+	return err
+}
+
+func (sf *StructForm) free() {
+	for _, f := range sf.fields {
+		if f != nil {
+			f.Free()
+		}
+	}
+	sf.fields = nil
+	for _, e := range sf.entries {
+		if e.typ != nil {
+			e.typ.Free()
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <curses.h>
+#include <term.h>
+
+static TERMINAL *goncurses_cur_term(void) {
+	return cur_term;
+}
+
+static char *goncurses_tparm(const char *s, const long *ip, char **sp) {
+	long p[9];
+	int i;
+
+	for (i = 0; i < 9; i++)
+		p[i] = sp[i] != NULL ? (long)(intptr_t)sp[i] : ip[i];
+	return tparm((char *)s, p[0], p[1], p[2], p[3], p[4], p[5], p[6], p[7],
+		p[8]);
+}
+
+static char *tputs_buf;
+static size_t tputs_len, tputs_cap;
+
+static int goncurses_tputs_outc(int c) {
+	if (tputs_len == tputs_cap) {
+		size_t cap = tputs_cap ? tputs_cap * 2 : 256;
+		char *buf = realloc(tputs_buf, cap);
+		if (buf == NULL)
+			return ERR;
+		tputs_buf = buf;
+		tputs_cap = cap;
+	}
+	tputs_buf[tputs_len++] = (char)c;
+	return c;
+}
+
+// goncurses_tputs applies any padding in s, returning the bytes which should
+// be sent to the terminal
+static char *goncurses_tputs(const char *s, int *n) {
+	tputs_len = 0;
+	if (tputs(s, 1, goncurses_tputs_outc) == ERR)
+		return NULL;
+	*n = (int)tputs_len;
+	return tputs_buf;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"unsafe"
+)
+
+// maxParams is the largest number of parameters a terminfo string
+// capability may take
+const maxParams = 9
+
+// Terminfo provides access to the terminfo description of a terminal. It can
+// be used to query any capability by its terminfo name, including the
+// user-defined capabilities understood by many modern terminals such as "Tc"
+// (true color), "Ms" (set selection) or "kLFT5" (control-left arrow), and to
+// send control sequences which are not otherwise provided by ncurses.
+type Terminfo struct {
+	term *C.TERMINAL
+}
+
+// terminalOutput records the file written to by each terminal set up with
+// NewTerm. Any other terminal writes to the standard output.
+var terminalOutput = make(map[*C.TERMINAL]*os.File)
+
+// setTerminalOutput records the file written to by the current terminal
+func setTerminalOutput(out *os.File) {
+	terminalOutput[C.goncurses_cur_term()] = out
+}
+
+// terminalModes records the modes turned on for each terminal, such as
+// bracketed paste, with the functions which turn them off again
+var terminalModes = make(map[*C.TERMINAL]map[string]func())
+
+// setMode records that the named mode is on, to be turned off by calling
+// reset, or that it is off if reset is nil
+func (t *Terminfo) setMode(name string, reset func()) {
+	if reset == nil {
+		delete(terminalModes[t.term], name)
+		return
+	}
+	if terminalModes[t.term] == nil {
+		terminalModes[t.term] = make(map[string]func())
+	}
+	terminalModes[t.term][name] = reset
+}
+
+// resetModes turns off the modes turned on for the current terminal before
+// it is restored to its normal state
+func resetModes() {
+	if ti := CurrentTerminfo(); ti != nil {
+		for _, reset := range terminalModes[ti.term] {
+			reset()
+		}
+	}
+}
+
+// forgetTerminal discards what is known about the terminal of a screen about
+// to be deleted
+func forgetTerminal(s *C.SCREEN) {
+	old := C.set_term(s)
+	delete(terminalOutput, C.goncurses_cur_term())
+	delete(terminalModes, C.goncurses_cur_term())
+	delete(modifiedKeyTables, C.goncurses_cur_term())
+	if old != nil && old != s {
+		C.set_term(old)
+	}
+}
+
+// CurrentTerminfo returns the terminfo description of the current terminal,
+// as set up by Init or NewTerm, or nil if there is none. The description
+// remains valid until the Screen it belongs to is deleted.
+func CurrentTerminfo() *Terminfo {
+	term := C.goncurses_cur_term()
+	if term == nil {
+		return nil
+	}
+	return &Terminfo{term}
+}
+
+// use makes t the current terminal, returning a function which restores the
+// previous one
+func (t *Terminfo) use() func() {
+	old := C.goncurses_cur_term()
+	if old == t.term {
+		return func() {}
+	}
+	C.set_curterm(t.term)
+	return func() { C.set_curterm(old) }
+}
+
+// Flag returns the value of the boolean capability cap. False is returned if
+// the terminal does not have the capability.
+func (t *Terminfo) Flag(cap string) bool {
+	defer t.use()()
+	ccap := C.CString(cap)
+	defer C.free(unsafe.Pointer(ccap))
+	return C.tigetflag(ccap) > 0
+}
+
+// Number returns the value of the numeric capability cap and true, or false
+// if the terminal does not have it
+func (t *Terminfo) Number(cap string) (int, bool) {
+	defer t.use()()
+	ccap := C.CString(cap)
+	defer C.free(unsafe.Pointer(ccap))
+	n := C.tigetnum(ccap)
+	if n < 0 {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// String returns the value of the string capability cap and true, or false
+// if the terminal does not have it. Parameterized capabilities are returned
+// unexpanded, see Params.
+func (t *Terminfo) String(cap string) (string, bool) {
+	defer t.use()()
+	ccap := C.CString(cap)
+	defer C.free(unsafe.Pointer(ccap))
+	s := C.tigetstr(ccap)
+	if s == nil || uintptr(unsafe.Pointer(s)) == ^uintptr(0) {
+		return "", false
+	}
+	return C.GoString(s), true
+}
+
+// Params returns the string capability cap with its parameters filled in.
+// Up to nine parameters may be given. Each must be a string or an integer
+// of any kind, signed or unsigned and of any size, including types defined
+// from them such as Key; integers must fit in a C long. For example:
+//
+//	ti.Params("cup", 10, 4)           // move the cursor to line 10, column 4
+//	ti.Params("Ms", "c", encodedText) // copy to the clipboard
+//
+// An error is returned if the terminal lacks the capability or the
+// parameters are not valid.
+func (t *Terminfo) Params(cap string, params ...interface{}) (string, error) {
+	if len(params) > maxParams {
+		return "", fmt.Errorf("Too many parameters for %s: %d",
+			cap, len(params))
+	}
+	var ip [maxParams]C.long
+	sp := (*[maxParams]*C.char)(C.calloc(maxParams,
+		C.size_t(unsafe.Sizeof((*C.char)(nil)))))
+	defer C.free(unsafe.Pointer(sp))
+	for i, p := range params {
+		var n int64
+		switch v := reflect.ValueOf(p); v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			n = v.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64, reflect.Uintptr:
+			if n = int64(v.Uint()); n < 0 {
+				return "", fmt.Errorf("Parameter %d for %s out of range: %v",
+					i+1, cap, p)
+			}
+		case reflect.String:
+			sp[i] = C.CString(v.String())
+			defer C.free(unsafe.Pointer(sp[i]))
+			continue
+		default:
+			return "", fmt.Errorf("Invalid parameter %d for %s: %T",
+				i+1, cap, p)
+		}
+		if ip[i] = C.long(n); int64(ip[i]) != n {
+			return "", fmt.Errorf("Parameter %d for %s out of range: %v",
+				i+1, cap, p)
+		}
+	}
+
+	defer t.use()()
+	ccap := C.CString(cap)
+	defer C.free(unsafe.Pointer(ccap))
+	s := C.tigetstr(ccap)
+	if s == nil || uintptr(unsafe.Pointer(s)) == ^uintptr(0) {
+		return "", errors.New("Terminal does not have capability " + cap)
+	}
+	res := C.goncurses_tparm(s, &ip[0], &sp[0])
+	if res == nil {
+		return "", errors.New("Failed to expand capability " + cap)
+	}
+	return C.GoString(res), nil
+}
+
+// Put sends s, typically the result of String or Params, to the terminal.
+// Any padding it contains is applied. The sequence is written immediately,
+// bypassing ncurses' own output, so it should be sent after the screen has
+// been updated with Refresh or Update.
+func (t *Terminfo) Put(s string) error {
+	defer t.use()()
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	var n C.int
+	buf := C.goncurses_tputs(cs, &n)
+	if buf == nil {
+		return fmt.Errorf("Failed to output %q", s)
+	}
+	out, ok := terminalOutput[t.term]
+	if !ok {
+		out = os.Stdout
+	}
+	_, err := out.Write(C.GoBytes(unsafe.Pointer(buf), n))
+	return err
+}
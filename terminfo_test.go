@@ -0,0 +1,67 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestTerminfo(t *testing.T) {
+	scr := newScreen(t, 10, 40)
+	defer scr.End()
+
+	ti := goncurses.CurrentTerminfo()
+	if ti == nil {
+		t.Fatal("no terminfo for the current terminal")
+	}
+	if !ti.Flag("am") || ti.Flag("hc") {
+		t.Error("expected am but not hc")
+	}
+	if n, ok := ti.Number("colors"); !ok || n != 256 {
+		t.Errorf("colors: got %d, %v", n, ok)
+	}
+	if _, ok := ti.Number("no-such-cap"); ok {
+		t.Error("expected unknown numeric capability to be absent")
+	}
+	if s, ok := ti.String("kLFT5"); !ok || s != "\x1b[1;5D" {
+		t.Errorf("kLFT5: got %q, %v", s, ok)
+	}
+
+	s, err := ti.Params("cup", 3, 5)
+	if err != nil || s != "\x1b[4;6H" {
+		t.Fatalf("cup: got %q, %v", s, err)
+	}
+	if s, err := ti.Params("Ms", "c", "aGk="); err != nil ||
+		s != "\x1b]52;c;aGk=\x07" {
+		t.Errorf("Ms: got %q, %v", s, err)
+	}
+	if s, err := ti.Params("cup", uint8(3), goncurses.Key(5)); err != nil ||
+		s != "\x1b[4;6H" {
+		t.Errorf("cup with uint8 and Key: got %q, %v", s, err)
+	}
+	if s, err := ti.Params("cup", int8(3), uint64(5)); err != nil ||
+		s != "\x1b[4;6H" {
+		t.Errorf("cup with int8 and uint64: got %q, %v", s, err)
+	}
+	if _, err := ti.Params("cup", uint64(1<<63), 2); err == nil {
+		t.Error("expected error for parameter out of range")
+	}
+	if _, err := ti.Params("cup", 1.5, 2); err == nil {
+		t.Error("expected error for invalid parameter type")
+	}
+	if _, err := ti.Params("no-such-cap"); err == nil {
+		t.Error("expected error for missing capability")
+	}
+
+	if err := ti.Put(s); err != nil {
+		t.Fatal(err)
+	}
+	if y, x := scr.Cursor(); y != 3 || x != 5 {
+		t.Errorf("cursor at %d,%d after Put, want 3,5", y, x)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package termtest
+
+import "os"
+
+func openPTY(rows, cols int) (*os.File, *os.File, error) {
+	return nil, nil, ErrUnsupported
+}
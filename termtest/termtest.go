@@ -0,0 +1,334 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package termtest runs goncurses against a headless, in-process virtual
+// terminal so that programs can be tested without a real TTY.
+//
+// A Screen allocates a pseudo-terminal of a fixed size, starts ncurses on it
+// with NewTerm and interprets everything ncurses writes into a grid of
+// cells. Tests draw as usual and then inspect the result:
+//
+// 	scr, err := termtest.New(termtest.DefaultTerm, 24, 80)
+// 	if err != nil {
+// 		t.Fatal(err)
+// 	}
+// 	defer scr.End()
+//
+// 	w := scr.Stdscr()
+// 	w.MovePrint(0, 0, "Hello")
+// 	w.Refresh()
+// 	if got := scr.Line(0); !strings.HasPrefix(got, "Hello") {
+// 		t.Errorf("got %q", got)
+// 	}
+//
+// Input can be delivered to the program with Send, exactly as if it had been
+// typed on the terminal. Pseudo-terminals are currently only supported on
+// Linux; elsewhere New returns ErrUnsupported, which tests should skip on.
+//
+// Like the rest of goncurses, a Screen must not be used concurrently. Only
+// one Screen should be active at a time.
+package termtest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// DefaultTerm is the terminal type recommended for tests. The interpreter
+// understands the control sequences used by the xterm family of terminal
+// descriptions.
+const DefaultTerm = "xterm-256color"
+
+// SyncTimeout is the longest a Screen will wait for the terminal output to
+// be interpreted before inspecting its contents.
+var SyncTimeout = 5 * time.Second
+
+// ErrUnsupported is returned by New on systems without pseudo-terminals
+var ErrUnsupported = errors.New("Pseudo-terminals are only supported on Linux")
+
+// ErrTimeout is returned by Compare, and the methods inspecting the screen
+// panic with it, if the terminal output is not interpreted within
+// SyncTimeout. The contents of the screen would otherwise be stale.
+var ErrTimeout = errors.New("Timed out waiting for terminal output")
+
+// syncMarker prefixes the operating system command written to the terminal
+// to determine when all prior output has been interpreted
+const syncMarker = "goncurses-termtest-sync;"
+
+type Screen struct {
+	screen        *gc.Screen
+	stdscr        *gc.Window
+	master, slave *os.File
+	done          chan struct{}
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	vt      *vt
+	written int
+	seen    int
+}
+
+// New starts ncurses on a new virtual terminal of rows by cols using the
+// given terminal type, which must be present in the terminfo database. The
+// locale is set to C.UTF-8 so that wide characters are handled the same on
+// every machine. End must be called once the Screen is no longer needed.
+func New(term string, rows, cols int) (*Screen, error) {
+	master, slave, err := openPTY(rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	s := &Screen{
+		master: master,
+		slave:  slave,
+		done:   make(chan struct{}),
+		vt:     newVT(rows, cols),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.vt.onOSC = s.osc
+	go s.read()
+
+	// ncurses prefers the environment to the terminal's own size so make
+	// sure they agree while it initializes
+	restore := setenv(map[string]string{
+		"LINES":   fmt.Sprint(rows),
+		"COLUMNS": fmt.Sprint(cols),
+		"LC_ALL":  "C.UTF-8",
+	})
+	s.screen, err = gc.NewTerm(term, slave, slave)
+	restore()
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	s.stdscr = gc.StdScr()
+	return s, nil
+}
+
+// setenv sets each of the environment variables in env, returning a
+// function which restores their previous values
+func setenv(env map[string]string) func() {
+	prev := make(map[string]*string)
+	for k, v := range env {
+		if old, ok := os.LookupEnv(k); ok {
+			prev[k] = &old
+		} else {
+			prev[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range prev {
+			if v == nil {
+				os.Unsetenv(k)
+				continue
+			}
+			os.Setenv(k, *v)
+		}
+	}
+}
+
+// End shuts down ncurses and releases the virtual terminal
+func (s *Screen) End() {
+	s.screen.End()
+	s.screen.Delete()
+	s.close()
+}
+
+func (s *Screen) close() {
+	s.slave.Close()
+	s.master.Close()
+	<-s.done
+}
+
+// read interprets the terminal output until the terminal is closed
+func (s *Screen) read() {
+	defer close(s.done)
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.master.Read(buf)
+		s.mu.Lock()
+		s.vt.Write(buf[:n])
+		s.mu.Unlock()
+		if err != nil {
+			s.mu.Lock()
+			s.seen = s.written
+			s.cond.Broadcast()
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// osc is called, with the lock held, for each operating system command
+// interpreted
+func (s *Screen) osc(cmd string) {
+	if !strings.HasPrefix(cmd, syncMarker) {
+		return
+	}
+	fmt.Sscan(cmd[len(syncMarker):], &s.seen)
+	s.cond.Broadcast()
+}
+
+// sync waits until all output written by ncurses so far has been
+// interpreted and then returns with the lock held. It panics with
+// ErrTimeout, without the lock held, if the output is not interpreted in
+// time.
+func (s *Screen) sync() {
+	if err := s.trySync(); err != nil {
+		s.mu.Unlock()
+		panic(err)
+	}
+}
+
+// trySync is sync returning ErrTimeout, with the lock held, rather than
+// panicking
+func (s *Screen) trySync() error {
+	s.mu.Lock()
+	s.written++
+	n := s.written
+	s.mu.Unlock()
+
+	fmt.Fprintf(s.slave, "\x1b]%s%d\x07", syncMarker, n)
+
+	timedOut := false
+	timer := time.AfterFunc(SyncTimeout, func() {
+		s.mu.Lock()
+		timedOut = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	s.mu.Lock()
+	for s.seen < n && !timedOut {
+		s.cond.Wait()
+	}
+	if s.seen < n {
+		return ErrTimeout
+	}
+	return nil
+}
+
+// Screen returns the goncurses Screen running on the virtual terminal
+func (s *Screen) Screen() *gc.Screen {
+	return s.screen
+}
+
+// Stdscr returns the standard screen Window of the virtual terminal
+func (s *Screen) Stdscr() *gc.Window {
+	return s.stdscr
+}
+
+// Size returns the number of rows and columns of the virtual terminal
+func (s *Screen) Size() (int, int) {
+	return s.vt.rows, s.vt.cols
+}
+
+// Send writes the string to the terminal's input, as if it had been typed.
+// Function keys are sent as the escape sequences the terminal would
+// generate, for example "\x1bOA" is the up arrow on an xterm.
+func (s *Screen) Send(input string) error {
+	if _, err := s.master.WriteString(input); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CellAt returns the cell displayed at the coordinates y, x. Note that it
+// uses ncurses idiom of taking y then x.
+func (s *Screen) CellAt(y, x int) Cell {
+	s.sync()
+	defer s.mu.Unlock()
+	if y < 0 || y >= s.vt.rows || x < 0 || x >= s.vt.cols {
+		return Cell{}
+	}
+	return s.vt.cells[y][x]
+}
+
+// Cursor returns the location of the terminal's cursor. Note that it uses
+// ncurses idiom of returning y then x.
+func (s *Screen) Cursor() (int, int) {
+	s.sync()
+	defer s.mu.Unlock()
+	return s.vt.y, s.vt.x
+}
+
+// Line returns the text displayed on row y, including any trailing blanks
+func (s *Screen) Line(y int) string {
+	s.sync()
+	defer s.mu.Unlock()
+	if y < 0 || y >= s.vt.rows {
+		return ""
+	}
+	return s.line(y)
+}
+
+func (s *Screen) line(y int) string {
+	var b strings.Builder
+	for _, c := range s.vt.cells[y] {
+		if c.Rune != 0 {
+			b.WriteRune(c.Rune)
+		}
+	}
+	return b.String()
+}
+
+// Text returns everything displayed on the terminal. Trailing blanks are
+// removed from each line and each line is terminated by a newline, which
+// makes the result suitable for comparing against golden files.
+func (s *Screen) Text() string {
+	s.sync()
+	defer s.mu.Unlock()
+	return s.text()
+}
+
+func (s *Screen) text() string {
+	var b strings.Builder
+	for y := range s.vt.cells {
+		b.WriteString(strings.TrimRight(s.line(y), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ErrMismatch is returned by Compare when the terminal does not display the
+// expected text
+var ErrMismatch = errors.New("Screen contents do not match")
+
+// Compare checks that the terminal displays the expected text, in the same
+// format as returned by Text. A descriptive error wrapping ErrMismatch is
+// returned if they differ, or ErrTimeout if the terminal output could not be
+// interpreted.
+func (s *Screen) Compare(expected string) error {
+	err := s.trySync()
+	got := s.text()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if got == expected {
+		return nil
+	}
+	gl, el := strings.Split(got, "\n"), strings.Split(expected, "\n")
+	for i := 0; i < len(gl) || i < len(el); i++ {
+		var g, e string
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if i < len(el) {
+			e = el[i]
+		}
+		if g != e {
+			return fmt.Errorf("%w: line %d: got %q, want %q",
+				ErrMismatch, i, g, e)
+		}
+	}
+	return ErrMismatch
+}
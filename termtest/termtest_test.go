@@ -0,0 +1,122 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package termtest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gc "github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/termtest"
+)
+
+func newScreen(t *testing.T) *termtest.Screen {
+	scr, err := termtest.New(termtest.DefaultTerm, 5, 20)
+	if errors.Is(err, termtest.ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scr
+}
+
+func TestText(t *testing.T) {
+	scr := newScreen(t)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.MovePrint(0, 0, "Hello")
+	w.MovePrint(2, 5, "World")
+	w.Refresh()
+
+	if err := scr.Compare("Hello\n\n     World\n\n\n"); err != nil {
+		t.Error(err)
+	}
+	if y, x := scr.Cursor(); y != 2 || x != 10 {
+		t.Errorf("cursor at %d, %d; want 2, 10", y, x)
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	scr := newScreen(t)
+	defer scr.End()
+
+	if err := gc.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	gc.InitPair(1, gc.C_RED, gc.C_BLUE)
+
+	w := scr.Stdscr()
+	w.AttrOn(gc.A_BOLD | gc.ColorPair(1))
+	w.MovePrint(1, 1, "X")
+	w.AttrOff(gc.A_BOLD | gc.ColorPair(1))
+	w.Print("Y")
+	w.Refresh()
+
+	c := scr.CellAt(1, 1)
+	if c.Rune != 'X' || c.Attr&gc.A_BOLD == 0 || c.Fg != int(gc.C_RED) ||
+		c.Bg != int(gc.C_BLUE) {
+		t.Errorf("got %+v; want bold red on blue X", c)
+	}
+	c = scr.CellAt(1, 2)
+	if c.Rune != 'Y' || c.Attr != 0 || c.Fg == int(gc.C_RED) {
+		t.Errorf("got %+v; want plain Y", c)
+	}
+}
+
+func TestLineDrawing(t *testing.T) {
+	scr := newScreen(t)
+	defer scr.End()
+
+	w, err := gc.NewWindow(3, 4, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Delete()
+	w.Box(0, 0)
+	w.Refresh()
+
+	expected := "\n ┌──┐\n │  │\n └──┘\n\n"
+	if err := scr.Compare(expected); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWide(t *testing.T) {
+	scr := newScreen(t)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.MovePrintW(0, 0, "日本語 é")
+	w.Refresh()
+
+	if got := strings.TrimRight(scr.Line(0), " "); got != "日本語 é" {
+		t.Errorf("got %q", got)
+	}
+	if c := scr.CellAt(0, 2); c.Rune != '本' {
+		t.Errorf("got %q at column 2; want '本'", c.Rune)
+	}
+	if got := strings.TrimRight(w.Line(0), " "); got != "日本語 é" {
+		t.Errorf("Window.Line returned %q", got)
+	}
+}
+
+func TestInput(t *testing.T) {
+	scr := newScreen(t)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	scr.Send("a\x1bOA")
+
+	if k := w.GetChar(); k != 'a' {
+		t.Errorf("got %q; want 'a'", k)
+	}
+	if k := w.GetChar(); k != gc.KEY_UP {
+		t.Errorf("got %d; want KEY_UP", k)
+	}
+}
@@ -0,0 +1,670 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package termtest
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+// DefaultColor is the value of Cell.Fg and Cell.Bg when the terminal's
+// default color is in use.
+const DefaultColor = -1
+
+// RGBColor is OR'd with a 24-bit red, green, blue value in Cell.Fg and
+// Cell.Bg when a direct color has been selected rather than a palette index.
+const RGBColor = 1 << 24
+
+// Cell is a single character position on the virtual terminal.
+type Cell struct {
+	Rune   rune    // character displayed, or zero for the right half of a wide character
+	Attr   gc.Char // OR'd A_* attributes; only the video attributes are used
+	Fg, Bg int     // palette index, DefaultColor or RGBColor|rgb
+}
+
+var blankCell = Cell{Rune: ' ', Fg: DefaultColor, Bg: DefaultColor}
+
+// decGraphics maps the DEC special graphics character set, used by terminals
+// for line drawing, onto the corresponding Unicode characters.
+var decGraphics = map[byte]rune{
+	'_': ' ', '`': '◆', 'a': '▒', 'b': '␉', 'c': '␌', 'd': '␍', 'e': '␊',
+	'f': '°', 'g': '±', 'h': '␤', 'i': '␋', 'j': '┘', 'k': '┐', 'l': '┌',
+	'm': '└', 'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽',
+	't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥',
+	'{': 'π', '|': '≠', '}': '£', '~': '·',
+}
+
+// parser states
+const (
+	stGround = iota
+	stEscape
+	stCharset
+	stCSI
+	stString
+	stStringEsc
+)
+
+type cursor struct {
+	y, x     int
+	pen      Cell
+	charsets [2]bool
+	shift    int
+}
+
+// vt interprets the output of a VT100/xterm compatible terminal into a grid
+// of cells. It understands the subset of control sequences that ncurses uses
+// with the xterm family of terminal descriptions.
+type vt struct {
+	rows, cols int
+	cells      [][]Cell
+	cursor
+	saved       cursor
+	wrapPending bool
+	autowrap    bool
+	insert      bool
+	top, bottom int
+	last        rune
+
+	state  int
+	utf8   []byte
+	params []byte
+	str    []byte
+	onOSC  func(string)
+}
+
+func newVT(rows, cols int) *vt {
+	t := &vt{rows: rows, cols: cols}
+	t.reset()
+	return t
+}
+
+func (t *vt) reset() {
+	t.cells = make([][]Cell, t.rows)
+	for y := range t.cells {
+		t.cells[y] = make([]Cell, t.cols)
+		for x := range t.cells[y] {
+			t.cells[y][x] = blankCell
+		}
+	}
+	t.cursor = cursor{pen: blankCell}
+	t.saved = t.cursor
+	t.wrapPending = false
+	t.autowrap = true
+	t.insert = false
+	t.top, t.bottom = 0, t.rows-1
+	t.state = stGround
+}
+
+// Write feeds terminal output into the interpreter. It never fails.
+func (t *vt) Write(p []byte) (int, error) {
+	for _, b := range p {
+		t.feed(b)
+	}
+	return len(p), nil
+}
+
+func (t *vt) feed(b byte) {
+	// CAN and SUB abort any sequence in progress, ESC starts a new one
+	switch {
+	case b == 0x18 || b == 0x1a:
+		t.state = stGround
+		return
+	case b == 0x1b && t.state != stString:
+		t.state = stEscape
+		t.params = t.params[:0]
+		return
+	}
+
+	switch t.state {
+	case stGround:
+		t.ground(b)
+	case stEscape:
+		t.escape(b)
+	case stCharset:
+		t.charset(b)
+	case stCSI:
+		switch {
+		case b >= 0x40 && b <= 0x7e:
+			t.state = stGround
+			t.csi(string(t.params), b)
+		case b < 0x20:
+			t.control(b)
+		default:
+			t.params = append(t.params, b)
+		}
+	case stString:
+		switch b {
+		case 0x07:
+			t.endString()
+		case 0x1b:
+			t.state = stStringEsc
+		default:
+			t.str = append(t.str, b)
+		}
+	case stStringEsc:
+		if b == '\\' {
+			t.endString()
+			return
+		}
+		t.state = stEscape
+		t.escape(b)
+	}
+}
+
+func (t *vt) ground(b byte) {
+	if len(t.utf8) == 0 && b < 0x80 {
+		if b < 0x20 || b == 0x7f {
+			t.control(b)
+			return
+		}
+		r := rune(b)
+		if t.charsets[t.shift] {
+			if g, ok := decGraphics[b]; ok {
+				r = g
+			}
+		}
+		t.put(r)
+		return
+	}
+	t.utf8 = append(t.utf8, b)
+	if utf8.FullRune(t.utf8) {
+		r, _ := utf8.DecodeRune(t.utf8)
+		t.utf8 = t.utf8[:0]
+		t.put(r)
+	}
+}
+
+func (t *vt) control(b byte) {
+	switch b {
+	case '\b':
+		t.wrapPending = false
+		if t.x > 0 {
+			t.x--
+		}
+	case '\t':
+		t.tab(1)
+	case '\n', '\v', '\f':
+		t.lineFeed()
+	case '\r':
+		t.wrapPending = false
+		t.x = 0
+	case 0x0e:
+		t.shift = 1
+	case 0x0f:
+		t.shift = 0
+	}
+}
+
+func (t *vt) escape(b byte) {
+	t.state = stGround
+	switch b {
+	case '[':
+		t.state = stCSI
+		t.params = t.params[:0]
+	case ']', 'P', '_', '^', 'X':
+		t.state = stString
+		t.str = t.str[:0]
+		if b != ']' {
+			t.str = append(t.str, 0)
+		}
+	case '(', ')':
+		t.state = stCharset
+		t.params = append(t.params[:0], b)
+	case '#', '%', ' ':
+		t.state = stCharset
+		t.params = t.params[:0]
+	case '7':
+		t.saved = t.cursor
+	case '8':
+		t.cursor = t.saved
+		t.wrapPending = false
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.x = 0
+		t.lineFeed()
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.reset()
+	}
+}
+
+func (t *vt) charset(b byte) {
+	t.state = stGround
+	if len(t.params) == 0 {
+		return
+	}
+	g := 0
+	if t.params[0] == ')' {
+		g = 1
+	}
+	t.charsets[g] = b == '0'
+}
+
+func (t *vt) endString() {
+	t.state = stGround
+	if len(t.str) > 0 && t.str[0] != 0 && t.onOSC != nil {
+		t.onOSC(string(t.str))
+	}
+}
+
+// put prints r at the cursor position, advancing the cursor
+func (t *vt) put(r rune) {
+	w := runeWidth(r)
+	if w == 0 {
+		return
+	}
+	if t.wrapPending && t.autowrap {
+		t.x = 0
+		t.lineFeed()
+	}
+	t.wrapPending = false
+	if w == 2 && t.x == t.cols-1 {
+		if !t.autowrap {
+			return
+		}
+		t.cells[t.y][t.x] = t.blank()
+		t.x = 0
+		t.lineFeed()
+	}
+	if t.insert {
+		t.insertBlanks(w)
+	}
+	t.clearWide(t.y, t.x)
+	cell := t.pen
+	cell.Rune = r
+	t.cells[t.y][t.x] = cell
+	if w == 2 {
+		t.clearWide(t.y, t.x+1)
+		cell.Rune = 0
+		t.cells[t.y][t.x+1] = cell
+	}
+	t.last = r
+	t.x += w
+	if t.x >= t.cols {
+		t.x = t.cols - 1
+		t.wrapPending = t.autowrap
+	}
+}
+
+// clearWide blanks the other half of any wide character at y, x before it
+// is overwritten
+func (t *vt) clearWide(y, x int) {
+	if t.cells[y][x].Rune == 0 && x > 0 {
+		t.cells[y][x-1] = t.blank()
+	}
+	if x+1 < t.cols && t.cells[y][x+1].Rune == 0 {
+		t.cells[y][x+1] = t.blank()
+	}
+}
+
+// blank returns an erased cell, which takes on the current background color
+func (t *vt) blank() Cell {
+	c := blankCell
+	c.Bg = t.pen.Bg
+	return c
+}
+
+func (t *vt) tab(n int) {
+	t.wrapPending = false
+	for ; n > 0 && t.x < t.cols-1; n-- {
+		t.x = (t.x/8 + 1) * 8
+	}
+	for ; n < 0 && t.x > 0; n++ {
+		t.x = (t.x - 1) / 8 * 8
+	}
+	if t.x > t.cols-1 {
+		t.x = t.cols - 1
+	}
+}
+
+func (t *vt) lineFeed() {
+	t.wrapPending = false
+	switch {
+	case t.y == t.bottom:
+		t.scrollUp(t.top, t.bottom, 1)
+	case t.y < t.rows-1:
+		t.y++
+	}
+}
+
+func (t *vt) reverseIndex() {
+	t.wrapPending = false
+	switch {
+	case t.y == t.top:
+		t.scrollDown(t.top, t.bottom, 1)
+	case t.y > 0:
+		t.y--
+	}
+}
+
+func (t *vt) blankLine() []Cell {
+	line := make([]Cell, t.cols)
+	for x := range line {
+		line[x] = t.blank()
+	}
+	return line
+}
+
+func (t *vt) scrollUp(top, bottom, n int) {
+	for ; n > 0; n-- {
+		copy(t.cells[top:bottom+1], t.cells[top+1:bottom+1])
+		t.cells[bottom] = t.blankLine()
+	}
+}
+
+func (t *vt) scrollDown(top, bottom, n int) {
+	for ; n > 0; n-- {
+		copy(t.cells[top+1:bottom+1], t.cells[top:bottom])
+		t.cells[top] = t.blankLine()
+	}
+}
+
+func (t *vt) insertBlanks(n int) {
+	line := t.cells[t.y]
+	if n > t.cols-t.x {
+		n = t.cols - t.x
+	}
+	copy(line[t.x+n:], line[t.x:])
+	for x := t.x; x < t.x+n; x++ {
+		line[x] = t.blank()
+	}
+}
+
+func (t *vt) erase(y, from, to int) {
+	for x := from; x < to && x < t.cols; x++ {
+		t.cells[y][x] = t.blank()
+	}
+}
+
+func (t *vt) moveTo(y, x int) {
+	t.wrapPending = false
+	t.y, t.x = clamp(y, 0, t.rows-1), clamp(x, 0, t.cols-1)
+}
+
+func (t *vt) csi(params string, final byte) {
+	private := ""
+	if params != "" && strings.IndexByte("<=>?!$ ", params[0]) >= 0 {
+		private, params = params[:1], params[1:]
+	}
+	args := parseParams(params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	if private != "" {
+		switch {
+		case private == "?" && (final == 'h' || final == 'l'):
+			for _, mode := range args {
+				t.privateMode(mode, final == 'h')
+			}
+		case private == "!" && final == 'p':
+			t.softReset()
+		}
+		return
+	}
+
+	switch final {
+	case '@':
+		t.insertBlanks(arg(0, 1))
+	case 'A':
+		min := 0
+		if t.y >= t.top {
+			min = t.top
+		}
+		t.moveTo(clamp(t.y-arg(0, 1), min, t.rows-1), t.x)
+	case 'B':
+		max := t.rows - 1
+		if t.y <= t.bottom {
+			max = t.bottom
+		}
+		t.moveTo(clamp(t.y+arg(0, 1), 0, max), t.x)
+	case 'C':
+		t.moveTo(t.y, t.x+arg(0, 1))
+	case 'D':
+		t.moveTo(t.y, t.x-arg(0, 1))
+	case 'E':
+		t.moveTo(t.y+arg(0, 1), 0)
+	case 'F':
+		t.moveTo(t.y-arg(0, 1), 0)
+	case 'G', '`':
+		t.moveTo(t.y, arg(0, 1)-1)
+	case 'H', 'f':
+		t.moveTo(arg(0, 1)-1, arg(1, 1)-1)
+	case 'I':
+		t.tab(arg(0, 1))
+	case 'J':
+		switch arg(0, 0) {
+		case 0:
+			t.erase(t.y, t.x, t.cols)
+			for y := t.y + 1; y < t.rows; y++ {
+				t.erase(y, 0, t.cols)
+			}
+		case 1:
+			for y := 0; y < t.y; y++ {
+				t.erase(y, 0, t.cols)
+			}
+			t.erase(t.y, 0, t.x+1)
+		case 2:
+			for y := 0; y < t.rows; y++ {
+				t.erase(y, 0, t.cols)
+			}
+		}
+	case 'K':
+		switch arg(0, 0) {
+		case 0:
+			t.erase(t.y, t.x, t.cols)
+		case 1:
+			t.erase(t.y, 0, t.x+1)
+		case 2:
+			t.erase(t.y, 0, t.cols)
+		}
+	case 'L', 'M':
+		if t.y < t.top || t.y > t.bottom {
+			return
+		}
+		n := clamp(arg(0, 1), 1, t.bottom-t.y+1)
+		if final == 'L' {
+			t.scrollDown(t.y, t.bottom, n)
+		} else {
+			t.scrollUp(t.y, t.bottom, n)
+		}
+		t.moveTo(t.y, 0)
+	case 'P':
+		n := clamp(arg(0, 1), 1, t.cols-t.x)
+		line := t.cells[t.y]
+		copy(line[t.x:], line[t.x+n:])
+		t.erase(t.y, t.cols-n, t.cols)
+	case 'S':
+		t.scrollUp(t.top, t.bottom, arg(0, 1))
+	case 'T':
+		t.scrollDown(t.top, t.bottom, arg(0, 1))
+	case 'X':
+		t.erase(t.y, t.x, t.x+arg(0, 1))
+	case 'Z':
+		t.tab(-arg(0, 1))
+	case 'b':
+		for n := arg(0, 1); n > 0; n-- {
+			t.put(t.last)
+		}
+	case 'd':
+		t.moveTo(arg(0, 1)-1, t.x)
+	case 'h', 'l':
+		for _, mode := range args {
+			if mode == 4 {
+				t.insert = final == 'h'
+			}
+		}
+	case 'm':
+		t.sgr(args)
+	case 'r':
+		top, bottom := arg(0, 1)-1, arg(1, t.rows)-1
+		if top < bottom && bottom < t.rows {
+			t.top, t.bottom = top, bottom
+			t.moveTo(0, 0)
+		}
+	case 's':
+		t.saved = t.cursor
+	case 'u':
+		t.cursor = t.saved
+		t.wrapPending = false
+	}
+}
+
+func (t *vt) privateMode(mode int, on bool) {
+	switch mode {
+	case 7:
+		t.autowrap = on
+	case 47, 1047, 1049:
+		if on {
+			t.saved = t.cursor
+		}
+		for y := 0; y < t.rows; y++ {
+			t.cells[y] = blankLineOf(t.cols)
+		}
+		if !on {
+			t.cursor = t.saved
+		}
+	}
+}
+
+func blankLineOf(cols int) []Cell {
+	line := make([]Cell, cols)
+	for x := range line {
+		line[x] = blankCell
+	}
+	return line
+}
+
+func (t *vt) softReset() {
+	t.pen = blankCell
+	t.charsets = [2]bool{}
+	t.shift = 0
+	t.insert = false
+	t.autowrap = true
+	t.top, t.bottom = 0, t.rows-1
+}
+
+func (t *vt) sgr(args []int) {
+	if len(args) == 0 {
+		args = []int{0}
+	}
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == 0:
+			t.pen = blankCell
+		case a == 1:
+			t.pen.Attr |= gc.A_BOLD
+		case a == 2:
+			t.pen.Attr |= gc.A_DIM
+		case a == 4:
+			t.pen.Attr |= gc.A_UNDERLINE
+		case a == 5:
+			t.pen.Attr |= gc.A_BLINK
+		case a == 7:
+			t.pen.Attr |= gc.A_REVERSE
+		case a == 8:
+			t.pen.Attr |= gc.A_INVIS
+		case a == 22:
+			t.pen.Attr &^= gc.A_BOLD | gc.A_DIM
+		case a == 24:
+			t.pen.Attr &^= gc.A_UNDERLINE
+		case a == 25:
+			t.pen.Attr &^= gc.A_BLINK
+		case a == 27:
+			t.pen.Attr &^= gc.A_REVERSE
+		case a == 28:
+			t.pen.Attr &^= gc.A_INVIS
+		case a >= 30 && a <= 37:
+			t.pen.Fg = a - 30
+		case a == 38 || a == 48:
+			var c int
+			c, i = extendedColor(args, i)
+			if a == 38 {
+				t.pen.Fg = c
+			} else {
+				t.pen.Bg = c
+			}
+		case a == 39:
+			t.pen.Fg = DefaultColor
+		case a >= 40 && a <= 47:
+			t.pen.Bg = a - 40
+		case a == 49:
+			t.pen.Bg = DefaultColor
+		case a >= 90 && a <= 97:
+			t.pen.Fg = a - 90 + 8
+		case a >= 100 && a <= 107:
+			t.pen.Bg = a - 100 + 8
+		}
+	}
+}
+
+// extendedColor decodes the 38/48 SGR color forms starting at args[i],
+// returning the color and the index of the last argument consumed
+func extendedColor(args []int, i int) (int, int) {
+	if i+2 < len(args) && args[i+1] == 5 {
+		return args[i+2], i + 2
+	}
+	if i+4 < len(args) && args[i+1] == 2 {
+		rgb := args[i+2]<<16 | args[i+3]<<8 | args[i+4]
+		return RGBColor | rgb, i + 4
+	}
+	return DefaultColor, len(args)
+}
+
+func parseParams(s string) []int {
+	if s == "" {
+		return nil
+	}
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || r == ':'
+	})
+	args := make([]int, len(fields))
+	for i, f := range fields {
+		args[i], _ = strconv.Atoi(f)
+	}
+	return args
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// wideRanges lists the East Asian wide and fullwidth ranges which occupy
+// two columns on the terminal
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f}, {0x2e80, 0x303e}, {0x3041, 0x33ff},
+	{0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe30, 0xfe4f},
+	{0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// runeWidth returns the number of columns r occupies on the terminal
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, rng := range wideRanges {
+		if r >= rng.lo && r <= rng.hi {
+			return 2
+		}
+	}
+	return 1
+}
@@ -0,0 +1,79 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package termtest
+
+import (
+	"testing"
+	"time"
+)
+
+func (t *vt) text(y int) string {
+	var s []rune
+	for _, c := range t.cells[y] {
+		if c.Rune != 0 {
+			s = append(s, c.Rune)
+		}
+	}
+	return string(s)
+}
+
+func TestVT(t *testing.T) {
+	tests := []struct {
+		name, input string
+		expected    []string
+	}{
+		{"print", "abc", []string{"abc ", "    ", "    "}},
+		{"autowrap", "abcdef", []string{"abcd", "ef  ", "    "}},
+		{"pending wrap", "abcd\r", []string{"abcd", "    ", "    "}},
+		{"cursor", "\x1b[2;3Hx\x1b[1Gy", []string{"    ", "y x ", "    "}},
+		{"erase", "abcd\x1b[1;2H\x1b[K", []string{"a   ", "    ", "    "}},
+		{"scroll", "a\r\nb\r\nc\r\nd", []string{"b   ", "c   ", "d   "}},
+		{"region", "\x1b[2;3ra\x1b[3;1Hb\r\nc", []string{"a   ", "b   ", "c   "}},
+		{"insert line", "a\r\nb\x1b[1;1H\x1b[L", []string{"    ", "a   ", "b   "}},
+		{"delete char", "abcd\x1b[1;2H\x1b[2P", []string{"ad  ", "    ", "    "}},
+		{"insert mode", "ac\x1b[1;2H\x1b[4hb", []string{"abc ", "    ", "    "}},
+		{"repeat", "x\x1b[2b", []string{"xxx ", "    ", "    "}},
+		{"graphics", "\x1b(0lqk\x1b(Bq", []string{"┌─┐q", "    ", "    "}},
+		{"wide", "日本x", []string{"日本", "x   ", "    "}},
+	}
+	for _, test := range tests {
+		v := newVT(3, 4)
+		v.Write([]byte(test.input))
+		for y, e := range test.expected {
+			if got := v.text(y); got != e {
+				t.Errorf("%s: line %d: got %q; want %q", test.name, y,
+					got, e)
+			}
+		}
+	}
+}
+
+func TestSyncTimeout(t *testing.T) {
+	s, err := New(DefaultTerm, 5, 20)
+	if err == ErrUnsupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.End()
+
+	// lose the markers so that the output never appears interpreted
+	s.mu.Lock()
+	s.vt.onOSC = func(string) {}
+	s.mu.Unlock()
+	defer func(timeout time.Duration) { SyncTimeout = timeout }(SyncTimeout)
+	SyncTimeout = 10 * time.Millisecond
+
+	if err := s.Compare("\n\n\n\n\n"); err != ErrTimeout {
+		t.Errorf("got %v; want ErrTimeout", err)
+	}
+	defer func() {
+		if r := recover(); r != ErrTimeout {
+			t.Errorf("got panic %v; want ErrTimeout", r)
+		}
+	}()
+	s.Line(0)
+}
@@ -12,6 +12,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"unicode/utf16"
 	"unsafe"
 )
 
@@ -31,17 +32,34 @@ func NewWindow(h, w, y, x int) (window *Window, err error) {
 // AddChar prints a single character to the window. The character can be
 // OR'd together with attributes and colors.
 func (w *Window) AddChar(ach Char) {
+	checkDispatch()
 	C.waddch(w.win, C.chtype(ach))
 }
 
 // MoveAddChar prints a single character to the window at the specified
 // y x coordinates. See AddChar for more info.
 func (w *Window) MoveAddChar(y, x int, ach Char) {
+	checkDispatch()
 	C.mvwaddch(w.win, C.int(y), C.int(x), C.chtype(ach))
 }
 
+// AddWChar prints a single wide character to the window along with its
+// attributes and color pair.
+func (w *Window) AddWChar(wc WChar) {
+	checkDispatch()
+	C.wadd_wch(w.win, wc.cchar())
+}
+
+// MoveAddWChar prints a single wide character to the window at the
+// specified y x coordinates. See AddWChar for more info.
+func (w *Window) MoveAddWChar(y, x int, wc WChar) {
+	checkDispatch()
+	C.mvwadd_wch(w.win, C.int(y), C.int(x), wc.cchar())
+}
+
 // Turn off character attribute.
 func (w *Window) AttrOff(attr Char) (err error) {
+	checkDispatch()
 	if C.ncurses_wattroff(w.win, C.int(attr)) == C.ERR {
 		err = errors.New(fmt.Sprintf("Failed to unset attribute: %s",
 			attrList[C.int(attr)]))
@@ -51,6 +69,7 @@ func (w *Window) AttrOff(attr Char) (err error) {
 
 // Turn on character attribute
 func (w *Window) AttrOn(attr Char) (err error) {
+	checkDispatch()
 	if C.ncurses_wattron(w.win, C.int(attr)) == C.ERR {
 		err = errors.New(fmt.Sprintf("Failed to set attribute: %s",
 			attrList[C.int(attr)]))
@@ -60,6 +79,7 @@ func (w *Window) AttrOn(attr Char) (err error) {
 
 // AttrSet sets the attributes to the given value
 func (w *Window) AttrSet(attr Char) error {
+	checkDispatch()
 	if C.ncurses_wattrset(w.win, C.int(attr)) == C.ERR {
 		return errors.New("Failed to set attributes")
 	}
@@ -69,17 +89,20 @@ func (w *Window) AttrSet(attr Char) error {
 // SetBackground fills the background with the supplied attributes and/or
 // characters.
 func (w *Window) SetBackground(attr Char) {
+	checkDispatch()
 	C.wbkgd(w.win, C.chtype(attr))
 }
 
 // Background returns the current background attributes
 func (w *Window) Background() Char {
+	checkDispatch()
 	return Char(C.ncurses_getbkgd(w.win))
 }
 
 // Border uses the characters supplied to draw a border around the window.
 // t, b, r, l, s correspond to top, bottom, right, left and side respectively.
 func (w *Window) Border(ls, rs, ts, bs, tl, tr, bl, br Char) error {
+	checkDispatch()
 	res := C.wborder(w.win, C.chtype(ls), C.chtype(rs), C.chtype(ts),
 		C.chtype(bs), C.chtype(tl), C.chtype(tr), C.chtype(bl),
 		C.chtype(br))
@@ -89,15 +112,84 @@ func (w *Window) Border(ls, rs, ts, bs, tl, tr, bl, br Char) error {
 	return nil
 }
 
+// BorderW behaves like Border but uses wide characters. Any WChar with a
+// zero Rune is replaced by the default line drawing character for that side.
+func (w *Window) BorderW(ls, rs, ts, bs, tl, tr, bl, br WChar) error {
+	checkDispatch()
+	res := C.wborder_set(w.win, ls.lineCChar(), rs.lineCChar(),
+		ts.lineCChar(), bs.lineCChar(), tl.lineCChar(), tr.lineCChar(),
+		bl.lineCChar(), br.lineCChar())
+	if res == C.ERR {
+		return errors.New("Failed to draw box around window")
+	}
+	return nil
+}
+
 // Box draws a border around the given window. For complete control over the
 // characters used to draw the border use Border()
 func (w *Window) Box(vch, hch Char) error {
+	checkDispatch()
 	if C.box(w.win, C.chtype(vch), C.chtype(hch)) == C.ERR {
 		return errors.New("Failed to draw box around window")
 	}
 	return nil
 }
 
+// Cells returns up to n characters, including their attributes and colors,
+// starting at the coordinates y, x. Fewer than n characters are returned if
+// the end of the line is reached first. The cursor position is unaffected.
+func (w *Window) Cells(y, x, n int) []Char {
+	checkDispatch()
+	if n <= 0 {
+		return nil
+	}
+	cy, cx := w.CursorYX()
+	defer w.Move(cy, cx)
+
+	buf := make([]C.chtype, n+1)
+	res := C.mvwinchnstr(w.win, C.int(y), C.int(x), &buf[0], C.int(n))
+	if res == C.ERR {
+		return nil
+	}
+	cells := make([]Char, res)
+	for i := range cells {
+		cells[i] = Char(buf[i])
+	}
+	return cells
+}
+
+// WCells is like Cells but returns wide characters, as written by AddWChar
+// or PrintW, along with their attributes and color pairs. Up to n columns
+// are read; as a double width character covers two of them, fewer than n
+// characters may be returned.
+func (w *Window) WCells(y, x, n int) []WChar {
+	checkDispatch()
+	if n <= 0 {
+		return nil
+	}
+	cy, cx := w.CursorYX()
+	defer w.Move(cy, cx)
+
+	buf := make([]C.cchar_t, n+1)
+	if C.mvwin_wchnstr(w.win, C.int(y), C.int(x), &buf[0], C.int(n)) ==
+		C.ERR {
+		return nil
+	}
+	cells := make([]WChar, 0, n)
+	for i := 0; i < n; i++ {
+		var wch C.wchar_t
+		var attr C.attr_t
+		var pair C.int
+		C.ncurses_getcchar(&buf[i], &wch, &attr, &pair)
+		if wch == 0 {
+			break
+		}
+		cells = append(cells, WChar{rune(wch), Char(attr &^ C.A_COLOR),
+			int32(pair)})
+	}
+	return cells
+}
+
 // Clears the screen and the underlying virtual screen. This forces the entire
 // screen to be rewritten from scratch. This will cause likely cause a
 // noticeable flicker because the screen is completely cleared before
@@ -105,6 +197,7 @@ func (w *Window) Box(vch, hch Char) error {
 // probably use the Erase() function. It is the same as called Erase() followed
 // by a call to ClearOk().
 func (w *Window) Clear() error {
+	checkDispatch()
 	if C.wclear(w.win) == C.ERR {
 		return errors.New("Failed to clear screen")
 	}
@@ -115,12 +208,14 @@ func (w *Window) Clear() error {
 // on stdscr then the whole screen is redrawn no matter which window has
 // Refresh() called on it. Defaults to False.
 func (w *Window) ClearOk(ok bool) {
+	checkDispatch()
 	C.clearok(w.win, C.bool(ok))
 }
 
 // Clear starting at the current cursor position, moving to the right, to the
 // bottom of window
 func (w *Window) ClearToBottom() error {
+	checkDispatch()
 	if C.wclrtobot(w.win) == C.ERR {
 		return errors.New("Failed to clear bottom of window")
 	}
@@ -130,6 +225,7 @@ func (w *Window) ClearToBottom() error {
 // Clear from the current cursor position, moving to the right, to the end
 // of the line
 func (w *Window) ClearToEOL() error {
+	checkDispatch()
 	if C.wclrtoeol(w.win) == C.ERR {
 		return errors.New("Failed to clear to end of line")
 	}
@@ -138,11 +234,13 @@ func (w *Window) ClearToEOL() error {
 
 // Color sets the foreground/background color pair for the entire window
 func (w *Window) Color(pair int16) {
+	checkDispatch()
 	C.wcolor_set(w.win, C.short(ColorPair(pair)), nil)
 }
 
 // ColorOff turns the specified color pair off
 func (w *Window) ColorOff(pair int16) error {
+	checkDispatch()
 	if C.ncurses_wattroff(w.win, C.int(ColorPair(pair))) == C.ERR {
 		return errors.New("Failed to enable color pair")
 	}
@@ -152,16 +250,30 @@ func (w *Window) ColorOff(pair int16) error {
 // Normally color pairs are turned on via attron() in ncurses but this
 // implementation chose to make it separate
 func (w *Window) ColorOn(pair int16) error {
+	checkDispatch()
 	if C.ncurses_wattron(w.win, C.int(ColorPair(pair))) == C.ERR {
 		return errors.New("Failed to enable color pair")
 	}
 	return nil
 }
 
+// Contents returns the text of every line in the window, from top to bottom.
+// See Line for details on how each line is read.
+func (w *Window) Contents() []string {
+	checkDispatch()
+	rows, _ := w.MaxYX()
+	lines := make([]string, rows)
+	for y := range lines {
+		lines[y] = w.Line(y)
+	}
+	return lines
+}
+
 // Copy is similar to Overlay and Overwrite but provides a finer grain of
 // control.
 func (w *Window) Copy(src *Window, sy, sx, dtr, dtc, dbr, dbc int,
 	overlay bool) error {
+	checkDispatch()
 	var ol int
 	if overlay {
 		ol = 1
@@ -178,6 +290,7 @@ func (w *Window) Copy(src *Window, sy, sx, dtr, dtc, dbr, dbc int,
 // characters to the right of that position one space to the left and appends
 // a blank character at the end.
 func (w *Window) DelChar() error {
+	checkDispatch()
 	if err := C.wdelch(w.win); err != C.OK {
 		return errors.New("An error occurred when trying to delete " +
 			"character")
@@ -189,6 +302,7 @@ func (w *Window) DelChar() error {
 // characters to the right of that position one space to the left and appends
 // a blank character at the end.
 func (w *Window) MoveDelChar(y, x int) error {
+	checkDispatch()
 	if err := C.mvwdelch(w.win, C.int(y), C.int(x)); err != C.OK {
 		return errors.New("An error occurred when trying to delete " +
 			"character")
@@ -196,9 +310,17 @@ func (w *Window) MoveDelChar(y, x int) error {
 	return nil
 }
 
+// Delay returns the input timeout set by Timeout: -1 if reading input
+// blocks, zero (0) if it does not or the number of milliseconds to wait
+func (w *Window) Delay() int {
+	checkDispatch()
+	return int(C.ncurses_wgetdelay(w.win))
+}
+
 // Delete the window. This function must be called to ensure memory is freed
 // to prevent memory leaks once you are done with the window.
 func (w *Window) Delete() error {
+	checkDispatch()
 	if C.delwin(w.win) == C.ERR {
 		return errors.New("Failed to delete window")
 	}
@@ -211,17 +333,20 @@ func (w *Window) Delete() error {
 // confining the derived window to the area of original window. See the
 // SubWindow function for additional notes.
 func (w *Window) Derived(height, width, y, x int) *Window {
+	checkDispatch()
 	return &Window{C.derwin(w.win, C.int(height), C.int(width), C.int(y),
 		C.int(x))}
 }
 
 // Duplicate the window, creating an exact copy.
 func (w *Window) Duplicate() *Window {
+	checkDispatch()
 	return &Window{C.dupwin(w.win)}
 }
 
 // Test whether the given coordinates are within the window or not
 func (w *Window) Enclose(y, x int) bool {
+	checkDispatch()
 	return bool(C.wenclose(w.win, C.int(y), C.int(x)))
 }
 
@@ -230,6 +355,7 @@ func (w *Window) Enclose(y, x int) bool {
 // updates to the terminal when frequently clearing and re-writing the window
 // or screen.
 func (w *Window) Erase() {
+	checkDispatch()
 	C.werase(w.win)
 }
 
@@ -238,6 +364,7 @@ func (w *Window) Erase() {
 // Timeout() has been set to zero or a positive value and no characters have
 // been received) the value returned will be zero (0)
 func (w *Window) GetChar() Key {
+	checkDispatch()
 	ch := C.wgetch(w.win)
 	if ch == C.ERR {
 		ch = 0
@@ -248,12 +375,14 @@ func (w *Window) GetChar() Key {
 // MoveGetChar moves the cursor to the given position and gets a character
 // from the input stream
 func (w *Window) MoveGetChar(y, x int) Key {
+	checkDispatch()
 	return Key(C.mvwgetch(w.win, C.int(y), C.int(x)))
 }
 
 // GetString reads at most 'n' characters entered by the user from the Window.
 // Attempts to enter greater than 'n' characters will elicit a 'beep'
 func (w *Window) GetString(n int) (string, error) {
+	checkDispatch()
 	cstr := make([]C.char, n)
 	if C.wgetnstr(w.win, (*C.char)(&cstr[0]), C.int(n)) == C.ERR {
 		return "", errors.New("Failed to retrieve string from input stream")
@@ -261,9 +390,53 @@ func (w *Window) GetString(n int) (string, error) {
 	return C.GoString(&cstr[0]), nil
 }
 
+// GetWChar retrieves a wide character or function key from the input stream.
+// Multi-byte input, such as "é" or "日", is decoded by ncurses and returned as
+// a single rune. If a function key (see the KEY_* constants) was pressed then
+// the rune is zero and the key is returned instead. An error is returned if
+// no input was available, including when an input timeout has expired.
+func (w *Window) GetWChar() (rune, Key, error) {
+	checkDispatch()
+	var wch C.wint_t
+	switch C.wget_wch(w.win, &wch) {
+	case C.KEY_CODE_YES:
+		return 0, Key(wch), nil
+	case C.OK:
+		return rune(wch), 0, nil
+	}
+	return 0, 0, errors.New("Failed to retrieve character from input stream")
+}
+
+// GetWString reads at most 'n' characters entered by the user from the
+// Window. Unlike GetString, 'n' is a count of characters (runes) rather than
+// bytes so multi-byte characters are accepted until the limit is reached.
+func (w *Window) GetWString(n int) (string, error) {
+	checkDispatch()
+	wstr := make([]C.wint_t, n+1)
+	if C.wgetn_wstr(w.win, &wstr[0], C.int(n)) == C.ERR {
+		return "", errors.New("Failed to retrieve string from input stream")
+	}
+	runes := make([]rune, 0, n)
+	for _, wch := range wstr {
+		if wch == 0 {
+			break
+		}
+		runes = append(runes, rune(wch))
+	}
+	if unsafe.Sizeof(C.wint_t(0)) == 2 {
+		u := make([]uint16, len(runes))
+		for i, r := range runes {
+			u[i] = uint16(r)
+		}
+		runes = utf16.Decode(u)
+	}
+	return string(runes), nil
+}
+
 // CursorYX returns the current cursor location in the Window. Note that it
 // uses ncurses idiom of returning y then x.
 func (w *Window) CursorYX() (int, int) {
+	checkDispatch()
 	var cy, cx C.int
 	C.ncurses_getyx(w.win, &cy, &cx)
 	return int(cy), int(cx)
@@ -272,34 +445,47 @@ func (w *Window) CursorYX() (int, int) {
 // HLine draws a horizontal line starting at y, x and ending at width using
 // the specified character
 func (w *Window) HLine(y, x int, ch Char, wid int) {
+	checkDispatch()
 	C.mvwhline(w.win, C.int(y), C.int(x), C.chtype(ch), C.int(wid))
 	return
 }
 
+// HLineW behaves like HLine but draws the line using a wide character. A
+// zero Rune draws the default horizontal line character.
+func (w *Window) HLineW(y, x int, wc WChar, wid int) {
+	checkDispatch()
+	C.mvwhline_set(w.win, C.int(y), C.int(x), wc.lineCChar(), C.int(wid))
+}
+
 // InChar returns the character at the current position in the curses window
 func (w *Window) InChar() Char {
+	checkDispatch()
 	return Char(C.winch(w.win))
 }
 
 // MoveInChar returns the character at the designated coordates in the curses
 // window
 func (w *Window) MoveInChar(y, x int) Char {
+	checkDispatch()
 	return Char(C.mvwinch(w.win, C.int(y), C.int(x)))
 }
 
 // IsCleared returns the value set in ClearOk
 func (w *Window) IsCleared() bool {
+	checkDispatch()
 	return bool(C.ncurses_is_cleared(w.win))
 }
 
 // IsKeypad returns the value set in Keypad
 func (w *Window) IsKeypad() bool {
+	checkDispatch()
 	return bool(C.ncurses_is_keypad(w.win))
 }
 
 // Keypad turns on/off the keypad characters, including those like the F1-F12
 // keys and the arrow keys
 func (w *Window) Keypad(keypad bool) error {
+	checkDispatch()
 	var err C.int
 	if err = C.keypad(w.win, C.bool(keypad)); err == C.ERR {
 		return errors.New("Unable to set keypad mode")
@@ -307,15 +493,34 @@ func (w *Window) Keypad(keypad bool) error {
 	return nil
 }
 
+// Line returns the text of line y of the window as a string, without any
+// attributes. The whole width of the window is read so the result includes
+// any trailing blanks. Wide characters are returned as a single rune even
+// if they occupy more than one column. The cursor position is unaffected.
+func (w *Window) Line(y int) string {
+	checkDispatch()
+	_, cols := w.MaxYX()
+	cy, cx := w.CursorYX()
+	defer w.Move(cy, cx)
+
+	buf := make([]C.wchar_t, cols+1)
+	if C.mvwinnwstr(w.win, C.int(y), 0, &buf[0], C.int(cols)) == C.ERR {
+		return ""
+	}
+	return goWideString(buf)
+}
+
 // LineTouched returns true if the line has been touched; returns false
 // otherwise
 func (w *Window) LineTouched(line int) bool {
+	checkDispatch()
 	return bool(C.is_linetouched(w.win, C.int(line)))
 }
 
 // Returns the maximum size of the Window. Note that it uses ncurses idiom
 // of returning y then x.
 func (w *Window) MaxYX() (int, int) {
+	checkDispatch()
 	var cy, cx C.int
 	C.ncurses_getmaxyx(w.win, &cy, &cx)
 	return int(cy), int(cx)
@@ -323,12 +528,14 @@ func (w *Window) MaxYX() (int, int) {
 
 // Move the cursor to the specified coordinates within the window
 func (w *Window) Move(y, x int) {
+	checkDispatch()
 	C.wmove(w.win, C.int(y), C.int(x))
 	return
 }
 
 // MoveWindow moves the location of the window to the specified coordinates
 func (w *Window) MoveWindow(y, x int) {
+	checkDispatch()
 	C.mvwin(w.win, C.int(y), C.int(x))
 	return
 }
@@ -340,6 +547,7 @@ func (w *Window) MoveWindow(y, x int) {
 // windows are involved because only the final output is
 // transmitted to the terminal.
 func (w *Window) NoutRefresh() {
+	checkDispatch()
 	C.wnoutrefresh(w.win)
 	return
 }
@@ -347,6 +555,7 @@ func (w *Window) NoutRefresh() {
 // Overlay copies overlapping sections of src window onto the destination
 // window. Non-blank elements are not overwritten.
 func (w *Window) Overlay(src *Window) error {
+	checkDispatch()
 	if C.overlay(src.win, w.win) == C.ERR {
 		return errors.New("Failed to overlay window")
 	}
@@ -357,6 +566,7 @@ func (w *Window) Overlay(src *Window) error {
 // window. This function is considered "destructive" by copying all
 // elements of src onto the destination window.
 func (w *Window) Overwrite(src *Window) error {
+	checkDispatch()
 	if C.overwrite(src.win, w.win) == C.ERR {
 		return errors.New("Failed to overwrite window")
 	}
@@ -366,6 +576,7 @@ func (w *Window) Overwrite(src *Window) error {
 // Parent returns a pointer to a Sub-window's parent, or nil if the window
 // has no parent
 func (w *Window) Parent() *Window {
+	checkDispatch()
 	p := C.ncurses_wgetparent(w.win)
 	if p == nil {
 		return nil
@@ -379,12 +590,14 @@ func (w *Window) Parent() *Window {
 // length before passing it as an argument.
 // window.Print("My line which should be clamped to 20 characters"[:20])
 func (w *Window) Print(args ...interface{}) {
+	checkDispatch()
 	w.Printf("%s", fmt.Sprint(args...))
 }
 
 // Printf functions the same as the standard library's fmt package. See Print
 // for more details.
 func (w *Window) Printf(format string, args ...interface{}) {
+	checkDispatch()
 	cstr := C.CString(fmt.Sprintf(format, args...))
 	defer C.free(unsafe.Pointer(cstr))
 
@@ -394,19 +607,31 @@ func (w *Window) Printf(format string, args ...interface{}) {
 // Println behaves the same as the standard library's fmt package.
 // See Print for more information.
 func (w *Window) Println(args ...interface{}) {
+	checkDispatch()
 	w.Printf("%s", fmt.Sprintln(args...))
 }
 
+// PrintW prints a string to the window using the wide character interface.
+// Each rune is handed to ncurses as a whole character rather than as a
+// sequence of bytes. See Print for more details.
+func (w *Window) PrintW(args ...interface{}) {
+	checkDispatch()
+	ws := wideString(fmt.Sprint(args...))
+	C.waddnwstr(w.win, &ws[0], C.int(len(ws)-1))
+}
+
 // MovePrint moves the cursor to the specified coordinates and prints the
 // supplied message. See Print for more details.The first two arguments are the
 // coordinates to print to.
 func (w *Window) MovePrint(y, x int, args ...interface{}) {
+	checkDispatch()
 	w.MovePrintf(y, x, "%s", fmt.Sprint(args...))
 }
 
 // MovePrintf moves the cursor to coordinates and prints the message using
 // the specified format. See Printf and MovePrint for more information.
 func (w *Window) MovePrintf(y, x int, format string, args ...interface{}) {
+	checkDispatch()
 	cstr := C.CString(fmt.Sprintf(format, args...))
 	defer C.free(unsafe.Pointer(cstr))
 
@@ -416,42 +641,68 @@ func (w *Window) MovePrintf(y, x int, format string, args ...interface{}) {
 // MovePrintln moves the cursor to coordinates and prints the message. See
 // Println and MovePrint for more details.
 func (w *Window) MovePrintln(y, x int, args ...interface{}) {
+	checkDispatch()
 	w.MovePrintf(y, x, "%s", fmt.Sprintln(args...))
 }
 
+// MovePrintW moves the cursor to the specified coordinates and prints the
+// message using the wide character interface. See PrintW for more details.
+func (w *Window) MovePrintW(y, x int, args ...interface{}) {
+	checkDispatch()
+	ws := wideString(fmt.Sprint(args...))
+	C.mvwaddnwstr(w.win, C.int(y), C.int(x), &ws[0], C.int(len(ws)-1))
+}
+
 // Refresh the window so it's contents will be displayed
 func (w *Window) Refresh() {
+	checkDispatch()
 	C.wrefresh(w.win)
 }
 
 // Resize the window to new height, width
 func (w *Window) Resize(height, width int) {
+	checkDispatch()
 	C.wresize(w.win, C.int(height), C.int(width))
 }
 
 // Scroll the contents of the window. Use a negative number to scroll up,
 // a positive number to scroll down. ScrollOk Must have been called prior.
 func (w *Window) Scroll(n int) {
+	checkDispatch()
 	C.wscrl(w.win, C.int(n))
 }
 
 // ScrollOk sets whether scrolling will work
 func (w *Window) ScrollOk(ok bool) {
+	checkDispatch()
 	C.scrollok(w.win, C.bool(ok))
 }
 
+// SetColorPair sets the color pair used for subsequent output to the window.
+// Unlike ColorOn, the pair is not encoded in the attribute bits so any pair
+// up to ColorPairs() may be used. See InitExtendedPair.
+func (w *Window) SetColorPair(pair int32) error {
+	checkDispatch()
+	if C.ncurses_wcolor_set(w.win, C.int(pair)) == C.ERR {
+		return errors.New("Failed to set color pair")
+	}
+	return nil
+}
+
 // SubWindow creates a new window of height and width at the coordinates
 // y, x.  This window shares memory with the original window so changes
 // made to one window are reflected in the other. It is necessary to call
 // Touch() on this window prior to calling Refresh in order for it to be
 // displayed.
 func (w *Window) Sub(height, width, y, x int) *Window {
+	checkDispatch()
 	return &Window{C.subwin(w.win, C.int(height), C.int(width), C.int(y),
 		C.int(x))}
 }
 
 // Standend turns off Standout mode, which is equivalent AttrSet(A_NORMAL)
 func (w *Window) Standend() error {
+	checkDispatch()
 	if C.ncurses_wstandend(w.win) == C.ERR {
 		return errors.New("Failed to set standend")
 	}
@@ -460,6 +711,7 @@ func (w *Window) Standend() error {
 
 // Standout is equivalent to AttrSet(A_STANDOUT)
 func (w *Window) Standout() error {
+	checkDispatch()
 	if C.ncurses_wstandout(w.win) == C.ERR {
 		return errors.New("Failed to set standout")
 	}
@@ -473,6 +725,7 @@ func (w *Window) Standout() error {
 // windows to match any updates made to the parent; and, SYNC_CURSOR, which
 // updates the cursor position only for all windows to match the parent window
 func (w *Window) Sync(sync int) {
+	checkDispatch()
 	switch sync {
 	case SYNC_DOWN:
 		C.wsyncdown(w.win)
@@ -489,12 +742,14 @@ func (w *Window) Sync(sync int) {
 // ==  0 - non-blocking; returns zero (0)
 // >=  1 - blocks for delay in milliseconds; returns zero (0)
 func (w *Window) Timeout(delay int) {
+	checkDispatch()
 	C.wtimeout(w.win, C.int(delay))
 }
 
 // Touch indicates that the window contains changes which should be updated
 // on the next call to Refresh
 func (w *Window) Touch() error {
+	checkDispatch()
 	if C.ncurses_touchwin(w.win) == C.ERR {
 		return errors.New("Failed to Touch window")
 	}
@@ -503,12 +758,14 @@ func (w *Window) Touch() error {
 
 // Touched returns true if window will be updated on the next Refresh
 func (w *Window) Touched() bool {
+	checkDispatch()
 	return bool(C.is_wintouched(w.win))
 }
 
 // Touchline behaves like Touch but only effects count number of lines,
 // beginning at start
 func (w *Window) TouchLine(start, count int) error {
+	checkDispatch()
 	if C.touchline(w.win, C.int(start), C.int(count)) == C.ERR {
 		return errors.New("Error in call to TouchLine")
 	}
@@ -518,19 +775,83 @@ func (w *Window) TouchLine(start, count int) error {
 // UnTouch indicates the window should not be updated on the next call to
 // Refresh
 func (w *Window) UnTouch() {
+	checkDispatch()
 	C.ncurses_untouchwin(w.win)
 }
 
 // VLine draws a vertical line starting at y, x and ending at height using
 // the specified character
 func (w *Window) VLine(y, x int, ch Char, wid int) {
+	checkDispatch()
 	C.mvwvline(w.win, C.int(y), C.int(x), C.chtype(ch), C.int(wid))
 }
 
+// VLineW behaves like VLine but draws the line using a wide character. A
+// zero Rune draws the default vertical line character.
+func (w *Window) VLineW(y, x int, wc WChar, wid int) {
+	checkDispatch()
+	C.mvwvline_set(w.win, C.int(y), C.int(x), wc.lineCChar(), C.int(wid))
+}
+
 // YX returns the current coordinates of the Window. Note that it uses
 // ncurses idiom of returning y then x.
 func (w *Window) YX() (int, int) {
+	checkDispatch()
 	var y, x C.int
 	C.ncurses_getbegyx(w.win, &y, &x)
 	return int(y), int(x)
 }
+
+// cchar converts the WChar into the C complex character type
+func (wc WChar) cchar() *C.cchar_t {
+	cc := new(C.cchar_t)
+	C.ncurses_setcchar(cc, C.wchar_t(wc.Rune), C.attr_t(wc.Attr),
+		C.int(wc.Pair))
+	return cc
+}
+
+// lineCChar is like cchar but returns nil for a zero Rune, which the line
+// and border drawing functions interpret as the default character
+func (wc WChar) lineCChar() *C.cchar_t {
+	if wc.Rune == 0 {
+		return nil
+	}
+	return wc.cchar()
+}
+
+// goWideString converts a NUL terminated C wide string into a Go string
+func goWideString(ws []C.wchar_t) string {
+	runes := make([]rune, 0, len(ws))
+	for _, wch := range ws {
+		if wch == 0 {
+			break
+		}
+		runes = append(runes, rune(wch))
+	}
+	if unsafe.Sizeof(C.wchar_t(0)) == 2 {
+		u := make([]uint16, len(runes))
+		for i, r := range runes {
+			u[i] = uint16(r)
+		}
+		runes = utf16.Decode(u)
+	}
+	return string(runes)
+}
+
+// wideString converts s into a NUL terminated C wide string. On platforms
+// with a 16-bit wchar_t, characters outside the BMP are encoded as UTF-16
+// surrogate pairs.
+func wideString(s string) []C.wchar_t {
+	runes := []rune(s)
+	ws := make([]C.wchar_t, 0, len(runes)+1)
+	if unsafe.Sizeof(C.wchar_t(0)) == 2 {
+		for _, u := range utf16.Encode(runes) {
+			ws = append(ws, C.wchar_t(u))
+		}
+	} else {
+		for _, r := range runes {
+			ws = append(ws, C.wchar_t(r))
+		}
+	}
+	return append(ws, 0)
+}
@@ -0,0 +1,108 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestGetWChar(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Keypad(true)
+	w.Timeout(1000)
+	scr.Send("é日\x1bOA")
+	for _, want := range []rune{'é', '日'} {
+		if r, k, err := w.GetWChar(); r != want || k != 0 || err != nil {
+			t.Errorf("got %q, %d, %v; want %q", r, k, err, want)
+		}
+	}
+	if r, k, err := w.GetWChar(); r != 0 || k != goncurses.KEY_UP ||
+		err != nil {
+		t.Errorf("got %q, %d, %v; want the up key", r, k, err)
+	}
+	w.Timeout(0)
+	if _, _, err := w.GetWChar(); err == nil {
+		t.Error("expected an error without input")
+	}
+}
+
+func TestGetWString(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	w := scr.Stdscr()
+	w.Timeout(1000)
+	scr.Send("naïve 日本\n")
+	if s, err := w.GetWString(10); s != "naïve 日本" || err != nil {
+		t.Errorf("got %q, %v", s, err)
+	}
+	// the limit counts characters rather than bytes
+	scr.Send("日本語\n")
+	if s, err := w.GetWString(2); s != "日本" || err != nil {
+		t.Errorf("got %q, %v; want the first two characters", s, err)
+	}
+}
+
+func TestCells(t *testing.T) {
+	scr := newScreen(t, 5, 20)
+	defer scr.End()
+
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	goncurses.InitPair(1, goncurses.C_RED, goncurses.C_BLUE)
+	goncurses.InitPair(2, goncurses.C_GREEN, goncurses.C_BLACK)
+
+	w := scr.Stdscr()
+	w.AttrOn(goncurses.A_BOLD | goncurses.ColorPair(1))
+	w.MovePrint(0, 0, "ab")
+	w.AttrOff(goncurses.A_BOLD | goncurses.ColorPair(1))
+	w.Print("c")
+	cells := w.Cells(0, 0, 3)
+	if len(cells) != 3 {
+		t.Fatalf("got %d cells; want 3", len(cells))
+	}
+	for i, want := range []goncurses.Char{'a', 'b'} {
+		if cells[i] != want|goncurses.A_BOLD|goncurses.ColorPair(1) {
+			t.Errorf("cell %d: got %x; want bold %q in pair 1", i,
+				cells[i], want)
+		}
+	}
+	if cells[2] != 'c' {
+		t.Errorf("cell 2: got %x; want a plain 'c'", cells[2])
+	}
+	if cells := w.Cells(0, 18, 5); len(cells) != 2 {
+		t.Errorf("got %d cells at the end of the line; want 2", len(cells))
+	}
+
+	w.MoveAddWChar(1, 0, goncurses.WChar{Rune: '日',
+		Attr: goncurses.A_UNDERLINE, Pair: 2})
+	w.AddWChar(goncurses.WChar{Rune: 'é', Attr: goncurses.A_BOLD, Pair: 1})
+	w.Move(3, 3)
+	// four columns hold the double width character and two others
+	wide := w.WCells(1, 0, 4)
+	if len(wide) != 3 {
+		t.Fatalf("got %d wide cells; want 3", len(wide))
+	}
+	want := []goncurses.WChar{
+		{Rune: '日', Attr: goncurses.A_UNDERLINE, Pair: 2},
+		{Rune: 'é', Attr: goncurses.A_BOLD, Pair: 1},
+		{Rune: ' '},
+	}
+	for i := range want {
+		if wide[i] != want[i] {
+			t.Errorf("wide cell %d: got %+v; want %+v", i, wide[i], want[i])
+		}
+	}
+	if y, x := w.CursorYX(); y != 3 || x != 3 {
+		t.Errorf("cursor moved to %d, %d", y, x)
+	}
+}
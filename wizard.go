@@ -0,0 +1,250 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package goncurses
+
+// #include <form.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// wizardButtons are the labels and actions of a Wizard's buttons. The
+// letter following "< " is underlined and may be typed with alt held, see
+// NewWizardKeymap.
+var wizardButtons = []struct{ label, action string }{
+	{"< Back >", "back"},
+	{"< Next >", "next"},
+	{"< Finish >", "finish"},
+}
+
+// Wizard steps through the pages of a form one at a time, such as when
+// setting something up. The first line of the wizard's window shows which
+// page is displayed, and its last line Back, Next and Finish buttons. The
+// user may only go on to the next page, or finish, once every field of the
+// page is valid.
+//
+// The wizard draws in its window, which must be refreshed by the
+// application in the usual way.
+type Wizard struct {
+	// Titles, if set, are shown with the page indicator
+	Titles []string
+
+	// Validate, if not nil, is called once the fields of a page have been
+	// validated by the form, before going on to the next page or finishing.
+	// The error it returns, if any, prevents the wizard from continuing.
+	Validate func(page int) error
+
+	form     *Form
+	win      *Window
+	buttons  []int // column of each button, or -1 if not shown
+	finished bool
+}
+
+// NewWizard returns a wizard for the form, which is displayed in win. The
+// form is placed in a derived window between the wizard's first and last
+// lines; it must not have been posted.
+func NewWizard(form *Form, win *Window) (*Wizard, error) {
+	h, w := win.MaxYX()
+	if h < 3 {
+		return nil, errors.New("Window too small for wizard")
+	}
+	if err := form.SetWindow(win); err != nil {
+		return nil, err
+	}
+	if err := form.SetSub(win.Derived(h-2, w, 1, 0)); err != nil {
+		return nil, err
+	}
+	return &Wizard{form: form, win: win}, nil
+}
+
+// Post posts the wizard's form and draws the wizard
+func (wz *Wizard) Post() error {
+	if err := wz.form.Post(); err != nil {
+		return err
+	}
+	wz.finished = false
+	wz.draw()
+	return nil
+}
+
+// UnPost unposts the wizard's form
+func (wz *Wizard) UnPost() error {
+	return wz.form.UnPost()
+}
+
+// Finished returns true once the user has finished the wizard
+func (wz *Wizard) Finished() bool {
+	return wz.finished
+}
+
+// Back shows the previous page. The form does not allow the current field
+// to be left while its contents are invalid.
+func (wz *Wizard) Back() error {
+	checkDispatch()
+	page := wz.form.Page()
+	if page == 0 {
+		return errors.New("Wizard is on the first page")
+	}
+	defer wz.draw()
+	return wz.form.SetPage(page - 1)
+}
+
+// Next validates the page shown and, if it is valid, shows the next one
+func (wz *Wizard) Next() error {
+	checkDispatch()
+	page := wz.form.Page()
+	if page == wz.form.PageCount()-1 {
+		return errors.New("Wizard is on the last page")
+	}
+	if err := wz.validate(); err != nil {
+		return err
+	}
+	defer wz.draw()
+	return wz.form.SetPage(page + 1)
+}
+
+// Finish validates the last page and, if it is valid, finishes the wizard
+func (wz *Wizard) Finish() error {
+	checkDispatch()
+	if wz.form.Page() != wz.form.PageCount()-1 {
+		return errors.New("Wizard is not on the last page")
+	}
+	if err := wz.validate(); err != nil {
+		return err
+	}
+	wz.finished = true
+	return nil
+}
+
+// Drive feeds the key event to the keymap, which should be created by
+// NewWizardKeymap, and performs the "back", "next" and "finish" actions or
+// passes the event to the form, see Form.Drive. The action is returned.
+func (wz *Wizard) Drive(km *Keymap, ev KeyEvent) (string, error) {
+	action, err := wz.form.Drive(km, ev)
+	if err == nil {
+		err = wz.perform(action)
+	}
+	return action, err
+}
+
+// HandleMouse performs the action of a button clicked with the first
+// button, which is returned, or moves to a field of the page which was
+// clicked. Other events are ignored.
+func (wz *Wizard) HandleMouse(me *MouseEvent) (string, error) {
+	checkDispatch()
+	if me.Button != 1 || me.Action != MOUSE_CLICK {
+		return "", nil
+	}
+	y, x := me.Y, me.X
+	if !wz.win.Enclose(y, x) {
+		return "", nil
+	}
+	by, bx := wz.win.YX()
+	h, _ := wz.win.MaxYX()
+	if y-by == h-1 {
+		for i, b := range wizardButtons {
+			left := wz.buttons[i]
+			if left >= 0 && x-bx >= left && x-bx < left+len(b.label) {
+				return b.action, wz.perform(b.action)
+			}
+		}
+		return "", nil
+	}
+	if hit := HitTest(y, x); hit != nil && hit.Form == wz.form &&
+		hit.Field != nil {
+		return "", wz.form.SetCurrent(hit.Field)
+	}
+	return "", nil
+}
+
+func (wz *Wizard) perform(action string) error {
+	switch action {
+	case "back":
+		return wz.Back()
+	case "next":
+		return wz.Next()
+	case "finish":
+		return wz.Finish()
+	}
+	return nil
+}
+
+// validate checks every field of the page shown, leaving the first which is
+// invalid as the current field. Fields are visited in turn, as the form
+// only validates the field being left, with the form's field hooks
+// suspended so that OnFieldEnter and OnFieldLeave are not called.
+func (wz *Wizard) validate() error {
+	f := wz.form.form
+	page := wz.form.Page()
+	current := C.current_field(f)
+	enter, leave := C.field_init(f), C.field_term(f)
+	C.set_field_init(f, nil)
+	C.set_field_term(f, nil)
+	defer func() {
+		C.set_field_init(f, enter)
+		C.set_field_term(f, leave)
+	}()
+	pages := wz.form.fieldPages()
+	for i, field := range wz.form.fields() {
+		if pages[i] != page || C.field_opts(field)&C.O_ACTIVE == 0 ||
+			field == C.current_field(f) {
+			continue
+		}
+		if err := C.set_current_field(f, field); err != C.E_OK {
+			return ncursesError(syscall.Errno(err))
+		}
+	}
+	if err := wz.form.Driver(REQ_VALIDATION); err != nil {
+		return err
+	}
+	C.set_current_field(f, current)
+	if wz.Validate != nil {
+		return wz.Validate(page)
+	}
+	return nil
+}
+
+// draw shows the page indicator and buttons
+func (wz *Wizard) draw() {
+	h, w := wz.win.MaxYX()
+	page, count := wz.form.Page(), wz.form.PageCount()
+	indicator := fmt.Sprintf("Step %d of %d", page+1, count)
+	if page < len(wz.Titles) && wz.Titles[page] != "" {
+		indicator += ": " + wz.Titles[page]
+	}
+	wz.win.Move(0, 0)
+	wz.win.ClearToEOL()
+	wz.win.MovePrint(0, 0, indicator)
+
+	enabled := []bool{page > 0, page < count-1, page == count-1}
+	wz.win.Move(h-1, 0)
+	wz.win.ClearToEOL()
+	wz.buttons = make([]int, len(wizardButtons))
+	x := w
+	for i := len(wizardButtons) - 1; i >= 0; i-- {
+		label := wizardButtons[i].label
+		x -= len(label) + 1
+		wz.buttons[i] = x
+		if x < 0 {
+			wz.buttons[i] = -1
+			continue
+		}
+		if !enabled[i] {
+			wz.win.AttrOn(A_DIM)
+		}
+		wz.win.MovePrint(h-1, x, label[:2])
+		wz.win.AttrOn(A_UNDERLINE)
+		wz.win.Print(label[2:3])
+		wz.win.AttrOff(A_UNDERLINE)
+		wz.win.Print(label[3:])
+		wz.win.AttrOff(A_DIM)
+	}
+}